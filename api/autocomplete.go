@@ -0,0 +1,56 @@
+package api
+
+import (
+	"context"
+	"strings"
+)
+
+const (
+	// MinAutocompleteLength is the shortest text searched. Shorter text returns no completions.
+	MinAutocompleteLength = 2
+	// AutocompleteTimeout bounds the time Elasticsearch spends collecting hits. Hits found before the timeout are
+	// still returned, which is preferred over a slow response while the user is typing.
+	AutocompleteTimeout = "50ms"
+)
+
+// autocompleteSourceFields limits the returned documents to the fields used by AddressResult.
+var autocompleteSourceFields = []string{"number", "house_number", "street_prefix", "street", "street_suffix", "unit_designator", "unit_id", "city", "state", "zip_5", "lat_long", "municipality", "township", "ward", "census_tract", "pin"}
+
+// Autocomplete returns up to size addresses completing the typed text, such as "1200 W MAD". Every word must match the
+// start of a word in the address, in order, and the last word may be partial.
+func (s *Searcher) Autocomplete(ctx context.Context, text string, size int) ([]AddressResult, error) {
+	text = strings.Join(strings.Fields(text), " ")
+	if len(text) < MinAutocompleteLength {
+		return []AddressResult{}, nil
+	}
+
+	res, err := s.search(ctx, buildAutocompleteQuery(text), size)
+	if err != nil {
+		return nil, err
+	}
+
+	completions := make([]AddressResult, 0, len(res.Hits.Hits))
+	for _, hit := range res.Hits.Hits {
+		completions = append(completions, toAddressResult(hit.Source))
+	}
+	return completions, nil
+}
+
+// buildAutocompleteQuery matches the text against the search_as_you_type full_address field and its shingle
+// subfields. Equally scored completions are ordered by house number.
+func buildAutocompleteQuery(text string) map[string]interface{} {
+	return map[string]interface{}{
+		"query": map[string]interface{}{
+			"multi_match": map[string]interface{}{
+				"query":    text,
+				"type":     "bool_prefix",
+				"operator": "and",
+				"fields":   []string{"full_address", "full_address._2gram", "full_address._3gram"},
+			},
+		},
+		"sort":             []interface{}{"_score", map[string]interface{}{"number": "asc"}},
+		"_source":          autocompleteSourceFields,
+		"track_total_hits": false,
+		"timeout":          AutocompleteTimeout,
+	}
+}
@@ -0,0 +1,66 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestBuildAutocompleteQueryUsesBoolPrefix(t *testing.T) {
+	encoded, err := json.Marshal(buildAutocompleteQuery("1200 W MAD"))
+	if err != nil {
+		t.Fatalf("Could not encode query %s", err)
+	}
+	query := string(encoded)
+
+	for _, expected := range []string{`"type":"bool_prefix"`, `"query":"1200 W MAD"`, `"full_address._3gram"`, `"timeout":"50ms"`} {
+		if !strings.Contains(query, expected) {
+			t.Errorf("Expected query to contain %s. query: %s", expected, query)
+		}
+	}
+}
+
+func TestAutocompleteEndpoint(t *testing.T) {
+	server := newStubServer(t, stubSearchResponse)
+
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/autocomplete?q=1200+W+MAD&limit=2", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200. actual: %d body: %s", rec.Code, rec.Body.String())
+	}
+
+	var body AutocompleteResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Could not decode response %s", err)
+	}
+	if len(body.Completions) != 2 {
+		t.Fatalf("Expected 2 completions. actual: %d", len(body.Completions))
+	}
+	if body.Completions[0].Address != "1200 W MADISON ST, CHICAGO, IL 60607" || body.Completions[0].Longitude != -87.6579 {
+		t.Errorf("Unexpected first completion %v", body.Completions[0])
+	}
+}
+
+func TestAutocompleteSkipsShortText(t *testing.T) {
+	searcher := newStubServer(t, stubSearchResponse).searcher
+
+	completions, err := searcher.Autocomplete(context.Background(), " 1 ", 5)
+	if err != nil || len(completions) != 0 {
+		t.Errorf("Expected no completions for short text. actual: %v err: %v", completions, err)
+	}
+}
+
+func TestAutocompleteEndpointRejectsBadParameters(t *testing.T) {
+	server := newStubServer(t, stubSearchResponse)
+
+	for _, target := range []string{"/autocomplete", "/autocomplete?q=", "/autocomplete?q=mad&limit=51"} {
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400 for %s. actual: %d", target, rec.Code)
+		}
+	}
+}
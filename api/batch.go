@@ -0,0 +1,231 @@
+package api
+
+import (
+	"context"
+	"cook-county-geocoder/parser"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	BatchWorkers = 8
+	// MatchThreshold is the minimum score for a batch row to be reported as matched rather than partial.
+	MatchThreshold = 0.8
+)
+
+// Batch match statuses written to the match_status column.
+const (
+	StatusMatched     = "matched"
+	StatusPartial     = "partial"
+	StatusNoMatch     = "no_match"
+	StatusEmpty       = "empty"
+	StatusUnparseable = "unparseable"
+	StatusError       = "error"
+)
+
+// BatchColumns names the input CSV columns that make up an address. Only Address is required.
+type BatchColumns struct {
+	Address string
+	City    string
+	Zip     string
+}
+
+// BatchStats counts batch rows by match status.
+type BatchStats struct {
+	Rows        int
+	Matched     int
+	Partial     int
+	NoMatch     int
+	Empty       int
+	Unparseable int
+	Errors      int
+}
+
+var batchResultHeaders = []string{"latitude", "longitude", "matched_address", "match_score", "match_status"}
+
+// GeocodeCsv reads a CSV with a header row, geocodes every row and writes the original rows back out with the
+// batchResultHeaders columns appended. Rows are geocoded concurrently but written in input order, and output is
+// flushed after each row so large files stream.
+func (s *Searcher) GeocodeCsv(ctx context.Context, in io.Reader, out io.Writer, columns BatchColumns) (BatchStats, error) {
+	stats := BatchStats{}
+	reader := csv.NewReader(in)
+	reader.FieldsPerRecord = -1
+	writer := csv.NewWriter(out)
+
+	headers, err := reader.Read()
+	if err != nil {
+		return stats, fmt.Errorf("could not read CSV header: %w", err)
+	}
+	indices, err := resolveBatchColumns(headers, columns)
+	if err != nil {
+		return stats, err
+	}
+	if err := writeBatchRow(writer, out, append(headers, batchResultHeaders...)); err != nil {
+		return stats, err
+	}
+
+	// Each row gets its own result channel. Queuing the channels in read order keeps output ordered while up to
+	// BatchWorkers rows are in flight.
+	ordered := make(chan chan []string, BatchWorkers)
+	readErr := make(chan error, 1)
+	go func() {
+		defer close(ordered)
+		for {
+			record, err := reader.Read()
+			if err == io.EOF {
+				readErr <- nil
+				return
+			}
+			if err != nil {
+				readErr <- fmt.Errorf("could not read CSV row: %w", err)
+				return
+			}
+			result := make(chan []string, 1)
+			select {
+			case ordered <- result:
+			case <-ctx.Done():
+				readErr <- ctx.Err()
+				return
+			}
+			go func() {
+				result <- append(record, s.geocodeBatchRow(ctx, record, indices)...)
+			}()
+		}
+	}()
+
+	var writeErr error
+	for result := range ordered {
+		row := <-result
+		if writeErr != nil {
+			continue
+		}
+		stats.count(row[len(row)-1])
+		writeErr = writeBatchRow(writer, out, row)
+	}
+	if writeErr != nil {
+		return stats, writeErr
+	}
+	return stats, <-readErr
+}
+
+// batchColumnIndices holds the resolved positions of BatchColumns. Optional columns that were not requested are -1.
+type batchColumnIndices struct {
+	address int
+	city    int
+	zip     int
+}
+
+func resolveBatchColumns(headers []string, columns BatchColumns) (batchColumnIndices, error) {
+	indices := batchColumnIndices{address: -1, city: -1, zip: -1}
+	if columns.Address == "" {
+		return indices, fmt.Errorf("address column name is required")
+	}
+
+	find := func(name string) (int, error) {
+		if name == "" {
+			return -1, nil
+		}
+		for i, header := range headers {
+			if strings.EqualFold(strings.TrimSpace(header), name) {
+				return i, nil
+			}
+		}
+		return -1, fmt.Errorf("column %s not found in CSV header %v", name, headers)
+	}
+
+	var err error
+	if indices.address, err = find(columns.Address); err != nil {
+		return indices, err
+	}
+	if indices.city, err = find(columns.City); err != nil {
+		return indices, err
+	}
+	if indices.zip, err = find(columns.Zip); err != nil {
+		return indices, err
+	}
+	return indices, nil
+}
+
+// geocodeBatchRow returns the values for batchResultHeaders. Failures are reported in the status column so one bad
+// row does not stop the batch. Addresses the parser rejects are unparseable, and error is left for search failures.
+func (s *Searcher) geocodeBatchRow(ctx context.Context, record []string, indices batchColumnIndices) []string {
+	query := strings.Join(nonEmpty(
+		fieldAt(record, indices.address),
+		fieldAt(record, indices.city),
+		fieldAt(record, indices.zip),
+	), " ")
+	if query == "" {
+		return []string{"", "", "", "", StatusEmpty}
+	}
+
+	candidates, err := s.Geocode(ctx, query, 1)
+	if errors.Is(err, parser.ErrEmptyAddress) || errors.Is(err, parser.ErrNoStreet) {
+		return []string{"", "", "", "", StatusUnparseable}
+	}
+	if err != nil {
+		log.Printf("Error geocoding batch row %q: %s\n", query, err)
+		return []string{"", "", "", "", StatusError}
+	}
+	if len(candidates) == 0 {
+		return []string{"", "", "", "", StatusNoMatch}
+	}
+
+	best := candidates[0]
+	status := StatusMatched
+	if best.Score < MatchThreshold {
+		status = StatusPartial
+	}
+	return []string{
+		strconv.FormatFloat(best.Latitude, 'f', -1, 64),
+		strconv.FormatFloat(best.Longitude, 'f', -1, 64),
+		best.Address,
+		strconv.FormatFloat(best.Score, 'f', 4, 64),
+		status,
+	}
+}
+
+func fieldAt(record []string, index int) string {
+	if index < 0 || index >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[index])
+}
+
+// writeBatchRow writes and flushes a single row, pushing it to HTTP clients when possible.
+func writeBatchRow(writer *csv.Writer, out io.Writer, row []string) error {
+	if err := writer.Write(row); err != nil {
+		return fmt.Errorf("could not write CSV row: %w", err)
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("could not write CSV row: %w", err)
+	}
+	if flusher, ok := out.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	return nil
+}
+
+func (b *BatchStats) count(status string) {
+	b.Rows++
+	switch status {
+	case StatusMatched:
+		b.Matched++
+	case StatusPartial:
+		b.Partial++
+	case StatusNoMatch:
+		b.NoMatch++
+	case StatusEmpty:
+		b.Empty++
+	case StatusUnparseable:
+		b.Unparseable++
+	default:
+		b.Errors++
+	}
+}
@@ -0,0 +1,100 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGeocodeCsvAppendsResultColumnsInOrder(t *testing.T) {
+	server := newStubServer(t, stubSearchResponse)
+	input := "id,street address,zip\n" +
+		"1,1200 W Madison St,60607\n" +
+		"2,,\n" +
+		"3,1200 W Madison,\n" +
+		"4,1200,60607\n"
+
+	var out bytes.Buffer
+	stats, err := server.searcher.GeocodeCsv(context.Background(), strings.NewReader(input), &out, BatchColumns{Address: "Street Address", Zip: "zip"})
+	if err != nil {
+		t.Fatalf("Expected no errors geocoding CSV. Found %v", err)
+	}
+
+	rows, err := csv.NewReader(&out).ReadAll()
+	if err != nil {
+		t.Fatalf("Could not read output CSV %s", err)
+	}
+	if len(rows) != 5 {
+		t.Fatalf("Expected header and 4 rows. actual: %v", rows)
+	}
+
+	expectedHeader := "id,street address,zip,latitude,longitude,matched_address,match_score,match_status"
+	if strings.Join(rows[0], ",") != expectedHeader {
+		t.Errorf("Unexpected header. actual: %v expected: %s", rows[0], expectedHeader)
+	}
+	for i, id := range []string{"1", "2", "3", "4"} {
+		if rows[i+1][0] != id {
+			t.Errorf("Expected rows in input order. row %d actual id: %s", i+1, rows[i+1][0])
+		}
+	}
+	if rows[1][5] != "1200 W MADISON ST, CHICAGO, IL 60607" || rows[1][7] != StatusMatched {
+		t.Errorf("Unexpected matched row %v", rows[1])
+	}
+	if rows[2][7] != StatusEmpty {
+		t.Errorf("Expected empty status for row without an address. actual: %v", rows[2])
+	}
+	if rows[4][7] != StatusUnparseable {
+		t.Errorf("Expected unparseable status for row without a street. actual: %v", rows[4])
+	}
+	if stats.Rows != 4 || stats.Matched != 2 || stats.Empty != 1 || stats.Unparseable != 1 || stats.Errors != 0 {
+		t.Errorf("Unexpected batch stats %+v", stats)
+	}
+}
+
+func TestGeocodeCsvReportsSearchFailuresAsErrors(t *testing.T) {
+	server := newStubServer(t, "not json")
+
+	var out bytes.Buffer
+	stats, err := server.searcher.GeocodeCsv(context.Background(), strings.NewReader("addr\n1200 W Madison St\n1200\n"), &out, BatchColumns{Address: "addr"})
+	if err != nil {
+		t.Fatalf("Expected no errors geocoding CSV. Found %v", err)
+	}
+	if stats.Errors != 1 || stats.Unparseable != 1 {
+		t.Errorf("Expected the search failure as an error and the row without a street as unparseable. actual: %+v", stats)
+	}
+}
+
+func TestGeocodeCsvRequiresAddressColumn(t *testing.T) {
+	server := newStubServer(t, stubSearchResponse)
+
+	var out bytes.Buffer
+	_, err := server.searcher.GeocodeCsv(context.Background(), strings.NewReader("id,street\n1,Madison\n"), &out, BatchColumns{Address: "address"})
+	if err == nil {
+		t.Errorf("Expected error when the address column is missing. No error returned.")
+	}
+}
+
+func TestBatchEndpoint(t *testing.T) {
+	server := newStubServer(t, stubSearchResponse)
+
+	req := httptest.NewRequest(http.MethodPost, "/batch?address_col=addr", strings.NewReader("addr\n1200 W Madison St\n"))
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "text/csv" {
+		t.Fatalf("Expected a CSV response. status: %d body: %s", rec.Code, rec.Body.String())
+	}
+	if !strings.Contains(rec.Body.String(), StatusMatched) {
+		t.Errorf("Expected matched row in response. body: %s", rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	server.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/batch?address_col=missing", strings.NewReader("addr\n1200 W Madison St\n")))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for a missing column. actual: %d", rec.Code)
+	}
+}
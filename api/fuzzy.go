@@ -0,0 +1,115 @@
+package api
+
+import (
+	"cook-county-geocoder/shared/phonetic"
+	"strings"
+	"unicode"
+)
+
+// FuzzyTokenCredit is the share of a street token's weight given when it only matches approximately, so misspelled
+// queries still resolve but score below exact matches.
+const FuzzyTokenCredit = 0.5
+
+// streetSimilarity is the share of tokens in either street that appear in both. Tokens that match a misspelling count
+// FuzzyTokenCredit instead of a whole token, and fuzzy is true when any token matched that way.
+func streetSimilarity(query string, doc string) (similarity float64, fuzzy bool) {
+	queryTokens := strings.Fields(strings.ToUpper(query))
+	docTokens := strings.Fields(strings.ToUpper(doc))
+	if len(queryTokens) == 0 && len(docTokens) == 0 {
+		return 1, false
+	}
+
+	used := make([]bool, len(docTokens))
+	unmatched := make([]string, 0, len(queryTokens))
+	exact := 0
+	for _, token := range queryTokens {
+		if i := unusedToken(docTokens, used, func(docToken string) bool { return docToken == token }); i >= 0 {
+			used[i] = true
+			exact++
+		} else {
+			unmatched = append(unmatched, token)
+		}
+	}
+	approximate := 0
+	for _, token := range unmatched {
+		if i := unusedToken(docTokens, used, func(docToken string) bool { return isMisspelling(token, docToken) }); i >= 0 {
+			used[i] = true
+			approximate++
+		}
+	}
+
+	matched := exact + approximate
+	similarity = (float64(exact) + FuzzyTokenCredit*float64(approximate)) / float64(len(queryTokens)+len(docTokens)-matched)
+	return similarity, approximate > 0
+}
+
+// unusedToken returns the index of the first token not yet used that matches, or -1.
+func unusedToken(tokens []string, used []bool, matches func(string) bool) int {
+	for i, token := range tokens {
+		if !used[i] && matches(token) {
+			return i
+		}
+	}
+	return -1
+}
+
+// isMisspelling reports whether the query token is within the edit distance Elasticsearch allows for fuzziness AUTO,
+// or sounds the same. Tokens with digits, like 63RD, must match exactly.
+func isMisspelling(queryToken string, docToken string) bool {
+	if strings.IndexFunc(queryToken+docToken, unicode.IsDigit) >= 0 {
+		return false
+	}
+	if editDistance(queryToken, docToken) <= autoFuzziness(queryToken) {
+		return true
+	}
+	return len(queryToken) >= 4 && phonetic.Soundex(queryToken) == phonetic.Soundex(docToken)
+}
+
+// autoFuzziness is the number of edits allowed by Elasticsearch fuzziness AUTO for a term of this length.
+func autoFuzziness(token string) int {
+	switch length := len([]rune(token)); {
+	case length <= 2:
+		return 0
+	case length <= 5:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// editDistance is the optimal string alignment distance, the number of insertions, deletions, substitutions and
+// adjacent transpositions needed to turn a into b. Transpositions count as one edit, as they do in Elasticsearch.
+func editDistance(a string, b string) int {
+	ar, br := []rune(a), []rune(b)
+	rows := make([][]int, len(ar)+1)
+	for i := range rows {
+		rows[i] = make([]int, len(br)+1)
+		rows[i][0] = i
+	}
+	for j := range rows[0] {
+		rows[0][j] = j
+	}
+	for i := 1; i <= len(ar); i++ {
+		for j := 1; j <= len(br); j++ {
+			cost := 1
+			if ar[i-1] == br[j-1] {
+				cost = 0
+			}
+			rows[i][j] = min(rows[i-1][j]+1, rows[i][j-1]+1, rows[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ar[i-1] == br[j-2] && ar[i-2] == br[j-1] {
+				rows[i][j] = min(rows[i][j], rows[i-2][j-2]+1)
+			}
+		}
+	}
+	return rows[len(ar)][len(br)]
+}
+
+func min(values ...int) int {
+	result := values[0]
+	for _, v := range values[1:] {
+		if v < result {
+			result = v
+		}
+	}
+	return result
+}
@@ -0,0 +1,59 @@
+package api
+
+import (
+	"cook-county-geocoder/parser"
+	"cook-county-geocoder/shared/mapping"
+	"math"
+	"testing"
+)
+
+func TestStreetSimilarity(t *testing.T) {
+	if similarity, fuzzy := streetSimilarity("MARTIN LUTHER KING", "martin luther king"); similarity != 1 || fuzzy {
+		t.Errorf("Expected identical streets to fully overlap. actual: %f fuzzy: %t", similarity, fuzzy)
+	}
+	if similarity, fuzzy := streetSimilarity("MARTIN LUTHER KING JR", "MARTIN LUTHER KING"); similarity != 0.75 || fuzzy {
+		t.Errorf("Expected partial overlap. actual: %f fuzzy: %t", similarity, fuzzy)
+	}
+	if similarity, fuzzy := streetSimilarity("MADSION", "MADISON"); similarity != FuzzyTokenCredit || !fuzzy {
+		t.Errorf("Expected a transposition to match fuzzily. actual: %f fuzzy: %t", similarity, fuzzy)
+	}
+	if similarity, fuzzy := streetSimilarity("ASHLEND", "ASHLAND"); similarity != FuzzyTokenCredit || !fuzzy {
+		t.Errorf("Expected a misspelling to match fuzzily. actual: %f fuzzy: %t", similarity, fuzzy)
+	}
+	if similarity, _ := streetSimilarity("63RD", "64TH"); similarity != 0 {
+		t.Errorf("Expected numbered streets to only match exactly. actual: %f", similarity)
+	}
+	if similarity, _ := streetSimilarity("STATE", "LAKE"); similarity != 0 {
+		t.Errorf("Expected different streets to not match. actual: %f", similarity)
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	cases := []struct {
+		a, b     string
+		expected int
+	}{
+		{"MADISON", "MADISON", 0},
+		{"MADSION", "MADISON", 1},
+		{"HALSTED", "HALSTEAD", 1},
+		{"KEDZIE", "KEDVALE", 3},
+		{"", "ELM", 3},
+	}
+	for _, c := range cases {
+		if actual := editDistance(c.a, c.b); actual != c.expected {
+			t.Errorf("Unexpected edit distance between %s and %s. actual: %d expected: %d", c.a, c.b, actual, c.expected)
+		}
+	}
+}
+
+func TestScoreCandidatePenalizesMisspelledStreet(t *testing.T) {
+	parsed, err := parser.Parse("1200 W Madsion St, Chicago 60607")
+	if err != nil {
+		t.Fatalf("Could not parse query %s", err)
+	}
+	doc := mapping.EsAddress{Number: 1200, StreetPrefix: "W", Street: "MADISON", StreetSuffix: "ST", City: "CHICAGO", Zip5: "60607"}
+	expected := 1 - streetWeight*(1-FuzzyTokenCredit)
+	if score := scoreCandidate(parsed, doc); math.Abs(score-expected) > 1e-9 {
+		t.Errorf("Expected the misspelled street to cost part of its weight. actual: %f expected: %f", score, expected)
+	}
+}
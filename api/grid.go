@@ -0,0 +1,56 @@
+package api
+
+import (
+	"cook-county-geocoder/shared/grid"
+	"net/http"
+	"strings"
+)
+
+// handleGrid serves GET /grid?q=<grid coordinate> and GET /grid?lat=<lat>&lon=<lon>, converting between Chicago grid
+// coordinates such as "800 N 1200 W" and latitude and longitude.
+func (s *Server) handleGrid(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if s.grid == nil {
+		writeError(w, http.StatusNotFound, "grid model is not loaded")
+		return
+	}
+
+	params := r.URL.Query()
+	if query := strings.TrimSpace(params.Get("q")); query != "" {
+		northSouth, eastWest, err := grid.Parse(query)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		lat, lon := s.grid.Locate(float64(northSouth), float64(eastWest))
+		writeJSON(w, http.StatusOK, gridResponse(query, northSouth, eastWest, lat, lon))
+		return
+	}
+
+	lat, err := parseFloatParam("lat", params.Get("lat"), -90, 90)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	lon, err := parseFloatParam("lon", params.Get("lon"), -180, 180)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	northSouth, eastWest := s.grid.Coordinate(lat, lon)
+	writeJSON(w, http.StatusOK, gridResponse("", northSouth, eastWest, lat, lon))
+}
+
+func gridResponse(query string, northSouth int, eastWest int, lat float64, lon float64) GridResponse {
+	return GridResponse{
+		Query:      query,
+		Grid:       grid.Format(northSouth, eastWest),
+		NorthSouth: northSouth,
+		EastWest:   eastWest,
+		Latitude:   lat,
+		Longitude:  lon,
+	}
+}
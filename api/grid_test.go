@@ -0,0 +1,74 @@
+package api
+
+import (
+	"cook-county-geocoder/shared/grid"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// testGridModel puts State and Madison at 41.882,-87.628 with 800 numbers to the mile.
+var testGridModel = grid.Model{
+	NorthSouth: grid.Axis{Intercept: 41.882, Slope: 0.0000181},
+	EastWest:   grid.Axis{Intercept: -87.628, Slope: 0.0000243},
+}
+
+func newGridServer(t *testing.T) *Server {
+	server := newStubServer(t, stubSearchResponse)
+	server.grid = &testGridModel
+	return server
+}
+
+func TestGridEndpointLocatesCoordinate(t *testing.T) {
+	rec := httptest.NewRecorder()
+	newGridServer(t).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/grid?q=800+N+1200+W", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200. actual: %d body: %s", rec.Code, rec.Body.String())
+	}
+
+	var body GridResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Could not decode response %s", err)
+	}
+	if body.Grid != "800 N 1200 W" || body.NorthSouth != 800 || body.EastWest != -1200 {
+		t.Errorf("Unexpected grid coordinate %v", body)
+	}
+	if math.Abs(body.Latitude-41.89648) > 1e-9 || math.Abs(body.Longitude+87.65716) > 1e-9 {
+		t.Errorf("Unexpected location %f,%f", body.Latitude, body.Longitude)
+	}
+}
+
+func TestGridEndpointReturnsCoordinateForLocation(t *testing.T) {
+	rec := httptest.NewRecorder()
+	newGridServer(t).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/grid?lat=41.89648&lon=-87.65716", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200. actual: %d body: %s", rec.Code, rec.Body.String())
+	}
+
+	var body GridResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Could not decode response %s", err)
+	}
+	if body.Grid != "800 N 1200 W" {
+		t.Errorf("Unexpected grid coordinate %v", body)
+	}
+}
+
+func TestGridEndpointErrors(t *testing.T) {
+	rec := httptest.NewRecorder()
+	newStubServer(t, stubSearchResponse).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/grid?q=800+N+1200+W", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 without a grid model. actual: %d", rec.Code)
+	}
+
+	server := newGridServer(t)
+	for _, target := range []string{"/grid", "/grid?q=800+N", "/grid?lat=41.9", "/grid?lat=91&lon=-87.6", "/grid?lat=NaN&lon=NaN", "/grid?lat=41.9&lon=-Inf"} {
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400 for %s. actual: %d", target, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package api
+
+import (
+	"context"
+	"cook-county-geocoder/parser"
+	"cook-county-geocoder/shared/mapping"
+	"strings"
+)
+
+const (
+	// InterpolationMaxGap is how far, in house numbers, the bracketing address points may be from the requested number.
+	InterpolationMaxGap = 200
+	// InterpolationConfidence scales the score of interpolated candidates, which are estimates rather than address
+	// points.
+	InterpolationConfidence = 0.85
+
+	// interpolationFetchSize is the number of points fetched on each side. Only the nearest is needed, the rest allow
+	// for longer street names such as MADISON PARK that the street match also finds.
+	interpolationFetchSize = 5
+)
+
+// interpolationSourceFields limits the fetched points to the fields used by AddressResult. Units are left out because
+// an interpolated number is a building point.
+var interpolationSourceFields = []string{"number", "street_prefix", "street", "street_suffix", "city", "state", "zip_5", "lat_long"}
+
+// interpolate estimates the location of a house number with no address point, such as 1203 on a block with only 1201
+// and 1205. The nearest numbers below and above with the same parity, on the same side of the same street and in the
+// same ZIP code as the matched candidate, are found and the location is placed between them in proportion to the
+// numbers. ok is false when the number is not bracketed on both sides, or has an address point after all.
+func (s *Searcher) interpolate(ctx context.Context, parsed parser.ParsedAddress, street AddressResult) (Candidate, bool, error) {
+	lower, found, err := s.nearestNumber(ctx, street, parsed.Number, false)
+	if err != nil || !found {
+		return Candidate{}, false, err
+	}
+	if lower.Number == parsed.Number {
+		return Candidate{}, false, nil
+	}
+	upper, found, err := s.nearestNumber(ctx, street, parsed.Number, true)
+	if err != nil || !found {
+		return Candidate{}, false, err
+	}
+
+	fraction := float64(parsed.Number-lower.Number) / float64(upper.Number-lower.Number)
+	doc := lower
+	doc.Number = parsed.Number
+	doc.LatLong = mapping.LatLong{
+		Latitude:  lower.LatLong.Latitude + fraction*(upper.LatLong.Latitude-lower.LatLong.Latitude),
+		Longitude: lower.LatLong.Longitude + fraction*(upper.LatLong.Longitude-lower.LatLong.Longitude),
+	}
+	_, fuzzy := streetSimilarity(parsed.Street, doc.Street)
+	return Candidate{
+		AddressResult: toAddressResult(doc),
+		Score:         scoreCandidate(parsed, s.acceptCity(parsed, doc)) * InterpolationConfidence,
+		Fuzzy:         fuzzy,
+		MatchType:     MatchInterpolated,
+	}, true, nil
+}
+
+// nearestNumber returns the building point of the street with the same parity nearest to number, above it when above
+// is set and otherwise at or below it. found is false when there is none within InterpolationMaxGap.
+func (s *Searcher) nearestNumber(ctx context.Context, street AddressResult, number int, above bool) (mapping.EsAddress, bool, error) {
+	res, err := s.search(ctx, buildInterpolationQuery(street, number, above), interpolationFetchSize)
+	if err != nil {
+		return mapping.EsAddress{}, false, err
+	}
+	for _, hit := range res.Hits.Hits {
+		if sameStreet(street, hit.Source) && hit.Source.Number%2 == number%2 {
+			return hit.Source, true, nil
+		}
+	}
+	return mapping.EsAddress{}, false, nil
+}
+
+// buildInterpolationQuery fetches the building points of the street with the same parity as number, nearest first.
+// Above fetches the numbers after it and otherwise the number itself and those before it, so each side of the bracket
+// is found however many points the block has. Points are limited to the ZIP code of the matched candidate, since
+// suburbs reuse street names such as MAIN ST, and unit points are left out.
+func buildInterpolationQuery(street AddressResult, number int, above bool) map[string]interface{} {
+	numbers, order := map[string]interface{}{"gte": number - InterpolationMaxGap, "lte": number}, "desc"
+	if above {
+		numbers, order = map[string]interface{}{"gt": number, "lte": number + InterpolationMaxGap}, "asc"
+	}
+	filter := []interface{}{
+		map[string]interface{}{
+			"match": map[string]interface{}{"street": map[string]interface{}{"query": street.Street, "operator": "and"}},
+		},
+		map[string]interface{}{"range": map[string]interface{}{"number": numbers}},
+		map[string]interface{}{
+			"script": map[string]interface{}{
+				"script": map[string]interface{}{
+					"source": "doc['number'].value % 2 == params.parity",
+					"params": map[string]interface{}{"parity": number % 2},
+				},
+			},
+		},
+	}
+	if street.StreetPrefix != "" {
+		filter = append(filter, termClause("street_prefix", street.StreetPrefix, 1))
+	}
+	if street.Zip5 != "" {
+		filter = append(filter, termClause("zip_5", street.Zip5, 1))
+	}
+	return map[string]interface{}{
+		"query": map[string]interface{}{
+			"bool": map[string]interface{}{
+				"filter":   filter,
+				"must_not": map[string]interface{}{"exists": map[string]interface{}{"field": "unit_id"}},
+			},
+		},
+		"sort":    []interface{}{map[string]interface{}{"number": order}},
+		"_source": interpolationSourceFields,
+	}
+}
+
+// sameStreet compares the full street name, since the street match also finds longer names such as MADISON PARK, and
+// the ZIP code.
+func sameStreet(street AddressResult, doc mapping.EsAddress) bool {
+	return strings.EqualFold(street.StreetPrefix, doc.StreetPrefix) &&
+		strings.EqualFold(street.Street, doc.Street) &&
+		strings.EqualFold(street.StreetSuffix, doc.StreetSuffix) &&
+		street.Zip5 == doc.Zip5
+}
+
+// hasNumber reports whether any candidate is an address point with the house number on the same street as street. The
+// same number on another street does not count.
+func hasNumber(candidates []Candidate, street AddressResult, number int) bool {
+	for _, candidate := range candidates {
+		doc := mapping.EsAddress{StreetPrefix: candidate.StreetPrefix, Street: candidate.Street, StreetSuffix: candidate.StreetSuffix, Zip5: candidate.Zip5}
+		if candidate.Number == number && candidate.MatchType == MatchAddress && sameStreet(street, doc) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,129 @@
+package api
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const stubNeighborResponse = `{
+  "hits": {
+    "hits": [
+      {"_id": "a", "_score": 6.0, "_source": {"number": 1201, "street_prefix": "W", "street": "MADISON", "street_suffix": "ST", "city": "CHICAGO", "state": "IL", "zip_5": "60607", "lat_long": {"lat": 41.8817, "lon": -87.6570}}},
+      {"_id": "b", "_score": 6.0, "_source": {"number": 1209, "street_prefix": "W", "street": "MADISON", "street_suffix": "ST", "city": "CHICAGO", "state": "IL", "zip_5": "60607", "lat_long": {"lat": 41.8817, "lon": -87.6578}}}
+    ]
+  }
+}`
+
+// The points below 1203 W Madison, nearest first. MADISON PARK is a different street.
+const stubBelowResponse = `{
+  "hits": {
+    "hits": [
+      {"_id": "e", "_source": {"number": 1203, "street_prefix": "W", "street": "MADISON PARK", "street_suffix": "", "city": "CHICAGO", "state": "IL", "zip_5": "60607", "lat_long": {"lat": 41.8000, "lon": -87.6000}}},
+      {"_id": "a", "_source": {"number": 1201, "street_prefix": "W", "street": "MADISON", "street_suffix": "ST", "city": "CHICAGO", "state": "IL", "zip_5": "60607", "lat_long": {"lat": 41.8817, "lon": -87.6570}}},
+      {"_id": "c", "_source": {"number": 1199, "street_prefix": "W", "street": "MADISON", "street_suffix": "ST", "city": "CHICAGO", "state": "IL", "zip_5": "60607", "lat_long": {"lat": 41.8817, "lon": -87.6568}}}
+    ]
+  }
+}`
+
+// The points above 1203 W Madison, nearest first. 1205 in 60153 is a W MADISON ST in another ZIP code.
+const stubAboveResponse = `{
+  "hits": {
+    "hits": [
+      {"_id": "f", "_source": {"number": 1205, "street_prefix": "W", "street": "MADISON", "street_suffix": "ST", "city": "MAYWOOD", "state": "IL", "zip_5": "60153", "lat_long": {"lat": 41.8810, "lon": -87.8430}}},
+      {"_id": "b", "_source": {"number": 1209, "street_prefix": "W", "street": "MADISON", "street_suffix": "ST", "city": "CHICAGO", "state": "IL", "zip_5": "60607", "lat_long": {"lat": 41.8817, "lon": -87.6578}}}
+    ]
+  }
+}`
+
+func TestBuildInterpolationQueryFetchesEachSide(t *testing.T) {
+	street := AddressResult{StreetPrefix: "W", Street: "MADISON", StreetSuffix: "ST", Zip5: "60607"}
+	below, _ := json.Marshal(buildInterpolationQuery(street, 1203, false))
+	above, _ := json.Marshal(buildInterpolationQuery(street, 1203, true))
+
+	for _, query := range []string{string(below), string(above)} {
+		for _, expected := range []string{`"term":{"zip_5":{"boost":1,"value":"60607"}}`, `"must_not":{"exists":{"field":"unit_id"}}`, `"params":{"parity":1}`} {
+			if !strings.Contains(query, expected) {
+				t.Errorf("Expected query to contain %s. query: %s", expected, query)
+			}
+		}
+	}
+	if !strings.Contains(string(below), `"gte":1003,"lte":1203`) || !strings.Contains(string(below), `"number":"desc"`) {
+		t.Errorf("Expected the numbers up to 1203, nearest first. query: %s", below)
+	}
+	if !strings.Contains(string(above), `"gt":1203,"lte":1403`) || !strings.Contains(string(above), `"number":"asc"`) {
+		t.Errorf("Expected the numbers after 1203, nearest first. query: %s", above)
+	}
+}
+
+func TestHasNumberOnlyCountsTheSameStreet(t *testing.T) {
+	street := AddressResult{StreetPrefix: "W", Street: "MADISON", StreetSuffix: "ST", Zip5: "60607"}
+	other := Candidate{AddressResult: AddressResult{Number: 1203, StreetPrefix: "W", Street: "MADISON PARK", Zip5: "60607"}, MatchType: MatchAddress}
+	if hasNumber([]Candidate{other}, street, 1203) {
+		t.Errorf("Expected the number on another street not to count.")
+	}
+	same := Candidate{AddressResult: AddressResult{Number: 1203, StreetPrefix: "W", Street: "MADISON", StreetSuffix: "ST", Zip5: "60607"}, MatchType: MatchAddress}
+	if !hasNumber([]Candidate{other, same}, street, 1203) {
+		t.Errorf("Expected the number on the street to count.")
+	}
+}
+
+// newInterpolationServer answers the query for each side of 1203 with its points and any other number with none.
+func newInterpolationServer(t *testing.T) *Server {
+	return newRoutingStubServer(t, func(body string) string {
+		switch {
+		case strings.Contains(body, `"lte":1203`):
+			return stubBelowResponse
+		case strings.Contains(body, `"gt":1203`):
+			return stubAboveResponse
+		case strings.Contains(body, `"range"`):
+			return `{"hits": {"hits": []}}`
+		}
+		return stubNeighborResponse
+	})
+}
+
+func TestGeocodeInterpolatesMissingNumber(t *testing.T) {
+	rec := httptest.NewRecorder()
+	newInterpolationServer(t).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/geocode?q=1203+W+Madison+St+60607", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200. actual: %d body: %s", rec.Code, rec.Body.String())
+	}
+
+	var body GeocodeResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Could not decode response %s", err)
+	}
+	if len(body.Candidates) != 3 {
+		t.Fatalf("Expected the interpolated candidate and both neighbors. actual: %v", body.Candidates)
+	}
+	best := body.Candidates[0]
+	if best.MatchType != MatchInterpolated || best.Address != "1203 W MADISON ST, CHICAGO, IL 60607" || best.Score != InterpolationConfidence {
+		t.Errorf("Unexpected interpolated candidate %v", best)
+	}
+	// A quarter of the way from 1201 to 1209.
+	if math.Abs(best.Latitude-41.8817) > 1e-9 || math.Abs(best.Longitude+87.6572) > 1e-9 {
+		t.Errorf("Unexpected interpolated location %f,%f", best.Latitude, best.Longitude)
+	}
+	if body.Candidates[1].MatchType != MatchAddress {
+		t.Errorf("Expected address points after the interpolated candidate. actual: %v", body.Candidates[1])
+	}
+}
+
+func TestGeocodeDoesNotExtrapolate(t *testing.T) {
+	rec := httptest.NewRecorder()
+	newInterpolationServer(t).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/geocode?q=1211+W+Madison+St", nil))
+
+	var body GeocodeResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Could not decode response %s", err)
+	}
+	for _, candidate := range body.Candidates {
+		if candidate.MatchType == MatchInterpolated {
+			t.Errorf("Expected no interpolation past the last address point. actual: %v", candidate)
+		}
+	}
+}
@@ -0,0 +1,341 @@
+package api
+
+import (
+	"context"
+	"cook-county-geocoder/parser"
+	"cook-county-geocoder/shared/geo"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Intersections are resolved from address points alone. The geotile aggregation finds the tiles where both streets
+// have points, then the points in and around those tiles are fetched and the closest pair across the two streets marks
+// each crossing.
+
+const (
+	// IntersectionTilePrecision is the geotile zoom level used to find where both streets have address points. Tiles
+	// are about 230 meters across in Cook County.
+	IntersectionTilePrecision = 17
+	// MaxIntersectionTiles bounds the number of tiles searched for points, most likely first.
+	MaxIntersectionTiles = 20
+	// MaxIntersectionGapMeters is the largest distance between points on the two streets that still counts as a
+	// crossing.
+	MaxIntersectionGapMeters = 150.0
+	// IntersectionSeparationMeters is the minimum distance between two crossings of the same streets, such as a street
+	// that curves across another twice or cross streets with the same names in different towns.
+	IntersectionSeparationMeters = 500.0
+
+	intersectionFetchSize = 5000
+	// crossingCellDegrees is the grid cell used to find nearby points. It must be wider than MaxIntersectionGapMeters
+	// in both directions, about 220 meters north to south and 165 east to west in Cook County.
+	crossingCellDegrees = 0.002
+)
+
+// intersectionSourceFields limits the fetched points to the fields used by AddressResult.
+var intersectionSourceFields = []string{"number", "street_prefix", "street", "street_suffix", "city", "state", "zip_5", "lat_long"}
+
+// geocodeIntersection returns up to size crossings of the two streets, best match first.
+func (s *Searcher) geocodeIntersection(ctx context.Context, intersection parser.Intersection, size int) ([]Candidate, error) {
+	first := intersectionStreetQuery(intersection.First, "first")
+	second := intersectionStreetQuery(intersection.Second, "second")
+
+	tilesRes, err := s.search(ctx, buildIntersectionTileQuery(first, second), 0)
+	if err != nil {
+		return nil, err
+	}
+	tiles, err := crossingTiles(tilesRes.Aggregations)
+	if err != nil {
+		return nil, err
+	}
+	if len(tiles) == 0 {
+		return []Candidate{}, nil
+	}
+
+	pointsRes, err := s.search(ctx, buildIntersectionPointQuery(first, second, tiles), intersectionFetchSize)
+	if err != nil {
+		return nil, err
+	}
+	firstPoints := make([]esHit, 0, len(pointsRes.Hits.Hits))
+	secondPoints := make([]esHit, 0, len(pointsRes.Hits.Hits))
+	for _, hit := range pointsRes.Hits.Hits {
+		for _, name := range hit.MatchedQueries {
+			switch name {
+			case "first":
+				firstPoints = append(firstPoints, hit)
+			case "second":
+				secondPoints = append(secondPoints, hit)
+			}
+		}
+	}
+
+	crossings := nearestCrossings(firstPoints, secondPoints)
+	candidates := make([]Candidate, 0, len(crossings))
+	for _, c := range crossings {
+		candidates = append(candidates, intersectionCandidate(intersection, c))
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].Score > candidates[j].Score })
+	if len(candidates) > size {
+		candidates = candidates[:size]
+	}
+	return candidates, nil
+}
+
+// intersectionStreetQuery matches the points of one street, named so hits can be told apart. A prefix in the query
+// is required, since State St has both N and S points.
+func intersectionStreetQuery(parsed parser.ParsedAddress, name string) map[string]interface{} {
+	filter := make([]interface{}, 0, 1)
+	if parsed.StreetPrefix != "" {
+		filter = append(filter, termClause("street_prefix", parsed.StreetPrefix, 1))
+	}
+	return map[string]interface{}{
+		"bool": map[string]interface{}{
+			"must":   []interface{}{streetClause(parsed.Street)},
+			"filter": filter,
+			"_name":  name,
+		},
+	}
+}
+
+// buildIntersectionTileQuery counts the points of each street per geotile.
+func buildIntersectionTileQuery(first map[string]interface{}, second map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"query": eitherStreet(first, second),
+		"aggs": map[string]interface{}{
+			"tiles": map[string]interface{}{
+				"geotile_grid": map[string]interface{}{
+					"field":     "lat_long",
+					"precision": IntersectionTilePrecision,
+					"size":      10000,
+				},
+				"aggs": map[string]interface{}{
+					"first":  map[string]interface{}{"filter": first},
+					"second": map[string]interface{}{"filter": second},
+				},
+			},
+		},
+	}
+}
+
+// buildIntersectionPointQuery fetches the points of both streets within the tiles and their neighbors.
+func buildIntersectionPointQuery(first map[string]interface{}, second map[string]interface{}, tiles []tile) map[string]interface{} {
+	boxes := make([]interface{}, 0, len(tiles))
+	for _, t := range tiles {
+		top, left := t.offset(-1, -1).topLeft()
+		bottom, right := t.offset(2, 2).topLeft()
+		boxes = append(boxes, map[string]interface{}{
+			"geo_bounding_box": map[string]interface{}{
+				"lat_long": map[string]interface{}{
+					"top_left":     map[string]interface{}{"lat": top, "lon": left},
+					"bottom_right": map[string]interface{}{"lat": bottom, "lon": right},
+				},
+			},
+		})
+	}
+
+	query := eitherStreet(first, second)
+	query["bool"].(map[string]interface{})["filter"] = map[string]interface{}{
+		"bool": map[string]interface{}{"should": boxes, "minimum_should_match": 1},
+	}
+	return map[string]interface{}{"query": query, "_source": intersectionSourceFields}
+}
+
+func eitherStreet(first map[string]interface{}, second map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"bool": map[string]interface{}{
+			"should":               []interface{}{first, second},
+			"minimum_should_match": 1,
+		},
+	}
+}
+
+// tile is a geotile grid cell at IntersectionTilePrecision.
+type tile struct {
+	x, y int
+}
+
+// parseTile reads a geotile_grid bucket key, "<zoom>/<x>/<y>".
+func parseTile(key string) (tile, error) {
+	parts := strings.Split(key, "/")
+	if len(parts) != 3 || parts[0] != strconv.Itoa(IntersectionTilePrecision) {
+		return tile{}, fmt.Errorf("unexpected geotile key %s", key)
+	}
+	x, xErr := strconv.Atoi(parts[1])
+	y, yErr := strconv.Atoi(parts[2])
+	if xErr != nil || yErr != nil {
+		return tile{}, fmt.Errorf("unexpected geotile key %s", key)
+	}
+	return tile{x: x, y: y}, nil
+}
+
+func (t tile) offset(dx int, dy int) tile {
+	return tile{x: t.x + dx, y: t.y + dy}
+}
+
+// topLeft returns the latitude and longitude of the tile's north west corner.
+func (t tile) topLeft() (float64, float64) {
+	n := math.Exp2(IntersectionTilePrecision)
+	lon := float64(t.x)/n*360 - 180
+	lat := math.Atan(math.Sinh(math.Pi*(1-2*float64(t.y)/n))) * 180 / math.Pi
+	return lat, lon
+}
+
+// crossingTiles returns the tiles with points of the first street and points of the second street in the same or a
+// neighboring tile, the tiles with the most points first.
+func crossingTiles(aggregations json.RawMessage) ([]tile, error) {
+	var aggs struct {
+		Tiles struct {
+			Buckets []struct {
+				Key   string `json:"key"`
+				First struct {
+					DocCount int `json:"doc_count"`
+				} `json:"first"`
+				Second struct {
+					DocCount int `json:"doc_count"`
+				} `json:"second"`
+			} `json:"buckets"`
+		} `json:"tiles"`
+	}
+	if len(aggregations) == 0 {
+		return nil, nil
+	}
+	if err := json.Unmarshal(aggregations, &aggs); err != nil {
+		return nil, fmt.Errorf("could not decode geotile aggregation: %w", err)
+	}
+
+	firstCounts := make(map[tile]int)
+	secondCounts := make(map[tile]int)
+	for _, bucket := range aggs.Tiles.Buckets {
+		t, err := parseTile(bucket.Key)
+		if err != nil {
+			return nil, err
+		}
+		if bucket.First.DocCount > 0 {
+			firstCounts[t] = bucket.First.DocCount
+		}
+		if bucket.Second.DocCount > 0 {
+			secondCounts[t] = bucket.Second.DocCount
+		}
+	}
+
+	tiles := make([]tile, 0)
+	counts := make(map[tile]int)
+	for t, count := range firstCounts {
+		nearby := 0
+		for dx := -1; dx <= 1; dx++ {
+			for dy := -1; dy <= 1; dy++ {
+				nearby += secondCounts[t.offset(dx, dy)]
+			}
+		}
+		if nearby > 0 {
+			tiles = append(tiles, t)
+			counts[t] = count + nearby
+		}
+	}
+	sort.Slice(tiles, func(i, j int) bool {
+		if counts[tiles[i]] != counts[tiles[j]] {
+			return counts[tiles[i]] > counts[tiles[j]]
+		}
+		return tiles[i].x < tiles[j].x || (tiles[i].x == tiles[j].x && tiles[i].y < tiles[j].y)
+	})
+	if len(tiles) > MaxIntersectionTiles {
+		tiles = tiles[:MaxIntersectionTiles]
+	}
+	return tiles, nil
+}
+
+// crossing is the closest pair of points on the two streets near where they meet.
+type crossing struct {
+	first  esHit
+	second esHit
+	gap    float64
+}
+
+// nearestCrossings pairs each point of the first street with the closest point of the second street, then keeps the
+// closest pairs that are at least IntersectionSeparationMeters apart, closest first.
+func nearestCrossings(firstPoints []esHit, secondPoints []esHit) []crossing {
+	cell := func(hit esHit) [2]int {
+		return [2]int{
+			int(math.Floor(hit.Source.LatLong.Latitude / crossingCellDegrees)),
+			int(math.Floor(hit.Source.LatLong.Longitude / crossingCellDegrees)),
+		}
+	}
+	grid := make(map[[2]int][]esHit)
+	for _, hit := range secondPoints {
+		grid[cell(hit)] = append(grid[cell(hit)], hit)
+	}
+
+	pairs := make([]crossing, 0)
+	for _, first := range firstPoints {
+		best := crossing{gap: math.Inf(1)}
+		c := cell(first)
+		for dLat := -1; dLat <= 1; dLat++ {
+			for dLon := -1; dLon <= 1; dLon++ {
+				for _, second := range grid[[2]int{c[0] + dLat, c[1] + dLon}] {
+					if second.Id == first.Id {
+						continue
+					}
+					gap := geo.Distance(first.Source.LatLong.Latitude, first.Source.LatLong.Longitude, second.Source.LatLong.Latitude, second.Source.LatLong.Longitude)
+					if gap < best.gap {
+						best = crossing{first: first, second: second, gap: gap}
+					}
+				}
+			}
+		}
+		if best.gap <= MaxIntersectionGapMeters {
+			pairs = append(pairs, best)
+		}
+	}
+	sort.SliceStable(pairs, func(i, j int) bool { return pairs[i].gap < pairs[j].gap })
+
+	crossings := make([]crossing, 0)
+	for _, pair := range pairs {
+		lat, lon := pair.midpoint()
+		separate := true
+		for _, kept := range crossings {
+			keptLat, keptLon := kept.midpoint()
+			if geo.Distance(lat, lon, keptLat, keptLon) < IntersectionSeparationMeters {
+				separate = false
+				break
+			}
+		}
+		if separate {
+			crossings = append(crossings, pair)
+		}
+	}
+	return crossings
+}
+
+func (c crossing) midpoint() (float64, float64) {
+	return geo.Midpoint(c.first.Source.LatLong.Latitude, c.first.Source.LatLong.Longitude, c.second.Source.LatLong.Latitude, c.second.Source.LatLong.Longitude)
+}
+
+// intersectionCandidate locates the crossing halfway between its two points. The score is the average of each street's
+// score against its point.
+func intersectionCandidate(intersection parser.Intersection, c crossing) Candidate {
+	lat, lon := c.midpoint()
+	firstDoc, secondDoc := c.first.Source, c.second.Source
+	_, firstFuzzy := streetSimilarity(intersection.First.Street, firstDoc.Street)
+	_, secondFuzzy := streetSimilarity(intersection.Second.Street, secondDoc.Street)
+
+	firstStreet := strings.Join(strings.Fields(firstDoc.StreetPrefix+" "+firstDoc.Street+" "+firstDoc.StreetSuffix), " ")
+	secondStreet := strings.Join(strings.Fields(secondDoc.StreetPrefix+" "+secondDoc.Street+" "+secondDoc.StreetSuffix), " ")
+	stateZip := strings.TrimSpace(firstDoc.State + " " + firstDoc.Zip5)
+
+	return Candidate{
+		AddressResult: AddressResult{
+			Address:   strings.Join(nonEmpty(firstStreet+" & "+secondStreet, firstDoc.City, stateZip), ", "),
+			City:      firstDoc.City,
+			State:     firstDoc.State,
+			Zip5:      firstDoc.Zip5,
+			Latitude:  lat,
+			Longitude: lon,
+		},
+		Score:     (scoreCandidate(intersection.First, firstDoc) + scoreCandidate(intersection.Second, secondDoc)) / 2,
+		Fuzzy:     firstFuzzy || secondFuzzy,
+		MatchType: MatchIntersection,
+	}
+}
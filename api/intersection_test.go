@@ -0,0 +1,109 @@
+package api
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// The tile holding State and Madison, and the points of each street around it.
+const stubTileResponse = `{
+  "hits": {"hits": []},
+  "aggregations": {"tiles": {"buckets": [
+    {"key": "17/33631/48713", "doc_count": 5, "first": {"doc_count": 3}, "second": {"doc_count": 2}},
+    {"key": "17/33700/48713", "doc_count": 4, "first": {"doc_count": 4}, "second": {"doc_count": 0}}
+  ]}}
+}`
+
+const stubIntersectionPointResponse = `{
+  "hits": {
+    "hits": [
+      {"_id": "s1", "matched_queries": ["first"], "_source": {"number": 1, "street_prefix": "N", "street": "STATE", "street_suffix": "ST", "city": "CHICAGO", "state": "IL", "zip_5": "60602", "lat_long": {"lat": 41.8818, "lon": -87.6279}}},
+      {"_id": "s2", "matched_queries": ["first"], "_source": {"number": 20, "street_prefix": "N", "street": "STATE", "street_suffix": "ST", "city": "CHICAGO", "state": "IL", "zip_5": "60602", "lat_long": {"lat": 41.8830, "lon": -87.6279}}},
+      {"_id": "s3", "matched_queries": ["first"], "_source": {"number": 200, "street_prefix": "N", "street": "STATE", "street_suffix": "ST", "city": "CHICAGO", "state": "IL", "zip_5": "60601", "lat_long": {"lat": 41.8900, "lon": -87.6279}}},
+      {"_id": "m1", "matched_queries": ["second"], "_source": {"number": 1, "street_prefix": "W", "street": "MADISON", "street_suffix": "ST", "city": "CHICAGO", "state": "IL", "zip_5": "60602", "lat_long": {"lat": 41.8821, "lon": -87.6275}}},
+      {"_id": "m2", "matched_queries": ["second"], "_source": {"number": 200, "street_prefix": "W", "street": "MADISON", "street_suffix": "ST", "city": "CHICAGO", "state": "IL", "zip_5": "60606", "lat_long": {"lat": 41.8821, "lon": -87.6330}}}
+    ]
+  }
+}`
+
+func TestTileTopLeft(t *testing.T) {
+	tl, err := parseTile("17/33631/48713")
+	if err != nil {
+		t.Fatalf("Expected no errors parsing a tile key. Found %v", err)
+	}
+	lat, lon := tl.topLeft()
+	nextLat, nextLon := tl.offset(1, 1).topLeft()
+	// The tile contains State and Madison and is about 230 meters across.
+	if !(lat >= 41.882 && nextLat <= 41.882 && lon <= -87.628 && nextLon >= -87.628) {
+		t.Errorf("Expected the tile to contain 41.882,-87.628. actual: %f,%f to %f,%f", lat, lon, nextLat, nextLon)
+	}
+	if _, err := parseTile("16/1/2"); err == nil {
+		t.Errorf("Expected an error for a tile at another precision")
+	}
+}
+
+func TestCrossingTilesRequiresBothStreetsNearby(t *testing.T) {
+	var res esSearchResponse
+	if err := json.Unmarshal([]byte(stubTileResponse), &res); err != nil {
+		t.Fatalf("Could not decode response %s", err)
+	}
+	tiles, err := crossingTiles(res.Aggregations)
+	if err != nil {
+		t.Fatalf("Expected no errors. Found %v", err)
+	}
+	if len(tiles) != 1 || tiles[0] != (tile{x: 33631, y: 48713}) {
+		t.Errorf("Expected only the tile with both streets. actual: %v", tiles)
+	}
+}
+
+func TestNearestCrossingsKeepsSeparateCrossings(t *testing.T) {
+	point := func(id string, lat float64, lon float64) esHit {
+		hit := esHit{Id: id}
+		hit.Source.LatLong.Latitude, hit.Source.LatLong.Longitude = lat, lon
+		return hit
+	}
+	first := []esHit{point("a1", 41.8818, -87.6279), point("a2", 41.8819, -87.6279), point("a3", 41.9500, -87.6279)}
+	second := []esHit{point("b1", 41.8821, -87.6275), point("b2", 41.9502, -87.6281), point("b3", 42.1000, -87.6279)}
+
+	crossings := nearestCrossings(first, second)
+	if len(crossings) != 2 {
+		t.Fatalf("Expected two crossings. actual: %v", crossings)
+	}
+	if crossings[0].first.Id != "a3" || crossings[0].second.Id != "b2" || crossings[1].second.Id != "b1" {
+		t.Errorf("Expected the closest pairs, closest first. actual: %v", crossings)
+	}
+}
+
+func TestGeocodeIntersectionEndpoint(t *testing.T) {
+	server := newRoutingStubServer(t, func(body string) string {
+		if strings.Contains(body, "geotile_grid") {
+			return stubTileResponse
+		}
+		return stubIntersectionPointResponse
+	})
+
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/geocode?q=State+St+%26+Madison+St", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200. actual: %d body: %s", rec.Code, rec.Body.String())
+	}
+
+	var body GeocodeResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Could not decode response %s", err)
+	}
+	if len(body.Candidates) != 1 {
+		t.Fatalf("Expected 1 candidate. actual: %v", body.Candidates)
+	}
+	best := body.Candidates[0]
+	if best.MatchType != MatchIntersection || best.Address != "N STATE ST & W MADISON ST, CHICAGO, IL 60602" || best.Score != 1 {
+		t.Errorf("Unexpected intersection candidate %v", best)
+	}
+	if math.Abs(best.Latitude-41.88195) > 1e-9 || math.Abs(best.Longitude+87.6277) > 1e-9 {
+		t.Errorf("Expected the midpoint of the closest points. actual: %f,%f", best.Latitude, best.Longitude)
+	}
+}
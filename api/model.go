@@ -0,0 +1,99 @@
+package api
+
+// GeocodeResponse is the body returned by the forward geocoding endpoint.
+type GeocodeResponse struct {
+	Query      string      `json:"query"`
+	Candidates []Candidate `json:"candidates"`
+}
+
+// ReverseResponse is the body returned by the reverse geocoding endpoint.
+type ReverseResponse struct {
+	Latitude   float64            `json:"latitude"`
+	Longitude  float64            `json:"longitude"`
+	Candidates []ReverseCandidate `json:"candidates"`
+}
+
+// AutocompleteResponse is the body returned by the autocomplete endpoint.
+type AutocompleteResponse struct {
+	Query       string          `json:"query"`
+	Completions []AddressResult `json:"completions"`
+}
+
+// ParcelResponse is the body returned by the parcel endpoint. Pin is the requested PIN without dashes and Addresses the
+// address points on the parcel, empty when none are. Total is the number of points on the parcel, and Truncated is set
+// when it is more than the MaxParcelAddresses returned.
+type ParcelResponse struct {
+	Pin       string          `json:"pin"`
+	Addresses []AddressResult `json:"addresses"`
+	Total     int             `json:"total"`
+	Truncated bool            `json:"truncated"`
+}
+
+// GridResponse is the body returned by the grid endpoint. NorthSouth and EastWest are the signed grid coordinate, north
+// and east positive, and Grid is the same coordinate as it is written, for example "800 N 1200 W".
+type GridResponse struct {
+	Query      string  `json:"query,omitempty"`
+	Grid       string  `json:"grid"`
+	NorthSouth int     `json:"north_south"`
+	EastWest   int     `json:"east_west"`
+	Latitude   float64 `json:"latitude"`
+	Longitude  float64 `json:"longitude"`
+}
+
+// AddressResult is an indexed address point as returned to API clients. HouseNumber is the house number as written,
+// such as 1234 1/2, and the unit fields are only set for unit level points. X and Y are the State Plane Illinois East
+// coordinates in feet, only set when requested with sr=3435. Municipality, Township, Ward and CensusTract name the areas
+// containing the address, when they were loaded with the data, and Pin is the 14 digit PIN of its parcel.
+type AddressResult struct {
+	Address        string  `json:"address"`
+	Number         int     `json:"number"`
+	HouseNumber    string  `json:"house_number,omitempty"`
+	StreetPrefix   string  `json:"street_prefix"`
+	Street         string  `json:"street"`
+	StreetSuffix   string  `json:"street_suffix"`
+	UnitDesignator string  `json:"unit_designator,omitempty"`
+	UnitId         string  `json:"unit_id,omitempty"`
+	City           string  `json:"city"`
+	State          string  `json:"state"`
+	Zip5           string  `json:"zip_5"`
+	Latitude       float64 `json:"latitude"`
+	Longitude      float64 `json:"longitude"`
+	X              float64 `json:"x,omitempty"`
+	Y              float64 `json:"y,omitempty"`
+	Municipality   string  `json:"municipality,omitempty"`
+	Township       string  `json:"township,omitempty"`
+	Ward           string  `json:"ward,omitempty"`
+	CensusTract    string  `json:"census_tract,omitempty"`
+	Pin            string  `json:"pin,omitempty"`
+}
+
+// Candidate is a single ranked address match. Score is between 0 and 1, where 1 means every part of the query matched.
+// Fuzzy is true when the street only matched a misspelling of its name. MatchType is one of the Match constants.
+type Candidate struct {
+	AddressResult
+	Score     float64 `json:"score"`
+	Fuzzy     bool    `json:"fuzzy"`
+	MatchType string  `json:"match_type"`
+}
+
+// Match types. An address is an indexed address point, an intersection is the meeting point of two streets and an
+// interpolated address is estimated from the address points on either side of it. A zip or city match is the centroid
+// of the address points in a ZIP code or city, for queries naming nothing else.
+const (
+	MatchAddress      = "address"
+	MatchIntersection = "intersection"
+	MatchInterpolated = "interpolated"
+	MatchZip          = "zip"
+	MatchCity         = "city"
+)
+
+// ReverseCandidate is an address point near the requested location, with the great circle distance to it.
+type ReverseCandidate struct {
+	AddressResult
+	DistanceMeters float64 `json:"distance_meters"`
+}
+
+// ErrorResponse is the body returned with any non 2xx status.
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
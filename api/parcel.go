@@ -0,0 +1,63 @@
+package api
+
+import (
+	"context"
+	"cook-county-geocoder/shared/parcel"
+	"sort"
+)
+
+// MaxParcelAddresses is the most address points returned for a parcel. Large condominium parcels have hundreds of units.
+const MaxParcelAddresses = 1000
+
+// Parcel returns the address points on a parcel, in street and house number order, and the number of points the
+// parcel has. A 14 digit PIN matches the points with that PIN, and a 10 digit PIN matches every unit number on the
+// parcel. Only the first MaxParcelAddresses points are returned, so total may be more than the points returned.
+func (s *Searcher) Parcel(ctx context.Context, pin string) ([]AddressResult, int, error) {
+	res, err := s.search(ctx, buildParcelQuery(pin), MaxParcelAddresses)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	addresses := make([]AddressResult, 0, len(res.Hits.Hits))
+	for _, hit := range res.Hits.Hits {
+		addresses = append(addresses, toAddressResult(hit.Source))
+	}
+	sort.SliceStable(addresses, func(i, j int) bool {
+		a, b := addresses[i], addresses[j]
+		if a.Street != b.Street {
+			return a.Street < b.Street
+		}
+		if a.Number != b.Number {
+			return a.Number < b.Number
+		}
+		if a.HouseNumber != b.HouseNumber {
+			return a.HouseNumber < b.HouseNumber
+		}
+		return a.UnitId < b.UnitId
+	})
+	return addresses, res.Hits.Total.Value, nil
+}
+
+// buildParcelQuery filters address points by PIN, or by PIN prefix for a 10 digit parcel number. The total is counted
+// exactly, since Elasticsearch stops counting at 10000 by default.
+func buildParcelQuery(pin string) map[string]interface{} {
+	filter := map[string]interface{}{"term": map[string]interface{}{"pin": pin}}
+	if len(pin) == parcel.ParcelLength {
+		filter = map[string]interface{}{"prefix": map[string]interface{}{"pin": pin}}
+	}
+	return map[string]interface{}{
+		"query": map[string]interface{}{
+			"bool": map[string]interface{}{"filter": filter},
+		},
+		"track_total_hits": true,
+	}
+}
+
+// parsePin accepts a 10 or 14 digit PIN with or without dashes and returns its digits.
+func parsePin(raw string) (string, error) {
+	pin, ok := parcel.Digits(raw)
+	if !ok || (len(pin) != parcel.PinLength && len(pin) != parcel.ParcelLength) {
+		return "", &paramError{name: "pin", value: raw}
+	}
+	return pin, nil
+}
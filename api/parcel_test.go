@@ -0,0 +1,84 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const stubParcelResponse = `{
+  "hits": {
+    "total": {"value": 3, "relation": "eq"},
+    "max_score": 0.0,
+    "hits": [
+      {"_id": "b", "_score": 0.0, "_source": {"number": 1202, "street_prefix": "W", "street": "MADISON", "street_suffix": "ST", "city": "CHICAGO", "state": "IL", "zip_5": "60607", "lat_long": {"lat": 41.8817, "lon": -87.6583}, "pin": "17084370120000"}},
+      {"_id": "c", "_score": 0.0, "_source": {"number": 1200, "street_prefix": "W", "street": "MADISON", "street_suffix": "ST", "unit_designator": "APT", "unit_id": "2", "city": "CHICAGO", "state": "IL", "zip_5": "60607", "lat_long": {"lat": 41.8817, "lon": -87.6579}, "pin": "17084370121002"}},
+      {"_id": "a", "_score": 0.0, "_source": {"number": 1200, "street_prefix": "W", "street": "MADISON", "street_suffix": "ST", "unit_designator": "APT", "unit_id": "1", "city": "CHICAGO", "state": "IL", "zip_5": "60607", "lat_long": {"lat": 41.8817, "lon": -87.6579}, "pin": "17084370121001"}}
+    ]
+  }
+}`
+
+func TestBuildParcelQuery(t *testing.T) {
+	encoded, _ := json.Marshal(buildParcelQuery("17084370121001"))
+	if !strings.Contains(string(encoded), `"filter":{"term":{"pin":"17084370121001"}}`) || !strings.Contains(string(encoded), `"track_total_hits":true`) {
+		t.Errorf("Expected a term filter for a 14 digit PIN. query: %s", encoded)
+	}
+	encoded, _ = json.Marshal(buildParcelQuery("1708437012"))
+	if !strings.Contains(string(encoded), `"filter":{"prefix":{"pin":"1708437012"}}`) {
+		t.Errorf("Expected a prefix filter for a 10 digit PIN. query: %s", encoded)
+	}
+}
+
+func TestParcelEndpoint(t *testing.T) {
+	server := newStubServer(t, stubParcelResponse)
+
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/parcel?pin=17-08-437-012", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200. actual: %d body: %s", rec.Code, rec.Body.String())
+	}
+
+	var body ParcelResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Could not decode response %s", err)
+	}
+	if body.Pin != "1708437012" || len(body.Addresses) != 3 || body.Total != 3 || body.Truncated {
+		t.Fatalf("Unexpected parcel response %v", body)
+	}
+	var pins []string
+	for _, address := range body.Addresses {
+		pins = append(pins, address.Pin)
+	}
+	if strings.Join(pins, ",") != "17084370121001,17084370121002,17084370120000" {
+		t.Errorf("Expected addresses in street, number and unit order. actual: %v", pins)
+	}
+}
+
+func TestParcelEndpointReportsTruncatedParcels(t *testing.T) {
+	server := newStubServer(t, strings.Replace(stubParcelResponse, `"value": 3`, `"value": 1500`, 1))
+
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/parcel?pin=17-08-437-012", nil))
+
+	var body ParcelResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Could not decode response %s", err)
+	}
+	if body.Total != 1500 || !body.Truncated || len(body.Addresses) != 3 {
+		t.Errorf("Expected a truncated parcel with the total count. actual: %v", body)
+	}
+}
+
+func TestParcelEndpointRejectsBadParameters(t *testing.T) {
+	server := newStubServer(t, stubParcelResponse)
+
+	for _, target := range []string{"/parcel", "/parcel?pin=", "/parcel?pin=17-08-437", "/parcel?pin=17-08-437-012-000A", "/parcel?pin=17084370120000&sr=3857"} {
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400 for %s. actual: %d", target, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package api
+
+import (
+	"context"
+	"cook-county-geocoder/parser"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Queries naming only a ZIP code or a city are located at the centroid of the address points in the ZIP code, or in
+// the ZIP codes the city is known by.
+
+// placeZips returns the ZIP codes to locate the place in, or nil when the query is not a place. A city without a ZIP
+// code must be in the ZIP code city table, otherwise it is more likely a street name such as MADISON.
+func (s *Searcher) placeZips(place parser.Place) []string {
+	var cityZips []string
+	if place.City != "" {
+		cityZips = s.zipCities.Zips(place.City)
+		if len(cityZips) == 0 {
+			return nil
+		}
+	}
+	if place.Zip5 != "" {
+		return []string{place.Zip5}
+	}
+	return cityZips
+}
+
+// geocodePlace returns the centroid of the address points in the ZIP codes, or no candidates when none are indexed.
+func (s *Searcher) geocodePlace(ctx context.Context, place parser.Place, zips []string) ([]Candidate, error) {
+	res, err := s.search(ctx, buildPlaceQuery(zips), 0)
+	if err != nil {
+		return nil, err
+	}
+	var aggs struct {
+		Centroid struct {
+			Location struct {
+				Lat float64 `json:"lat"`
+				Lon float64 `json:"lon"`
+			} `json:"location"`
+			Count int `json:"count"`
+		} `json:"centroid"`
+	}
+	if len(res.Aggregations) > 0 {
+		if err := json.Unmarshal(res.Aggregations, &aggs); err != nil {
+			return nil, fmt.Errorf("could not decode centroid aggregation: %w", err)
+		}
+	}
+	if aggs.Centroid.Count == 0 {
+		return []Candidate{}, nil
+	}
+
+	candidate := Candidate{
+		AddressResult: AddressResult{
+			City:      place.City,
+			State:     place.State,
+			Zip5:      place.Zip5,
+			Latitude:  aggs.Centroid.Location.Lat,
+			Longitude: aggs.Centroid.Location.Lon,
+		},
+		Score:     s.scorePlace(place),
+		MatchType: MatchCity,
+	}
+	if place.Zip5 != "" {
+		candidate.MatchType = MatchZip
+		// The preferred city and state of the ZIP code fill in what the query left out.
+		if cities, ok := s.zipCities[place.Zip5]; ok {
+			if candidate.City == "" {
+				candidate.City = cities.City
+			}
+			if candidate.State == "" {
+				candidate.State = cities.State
+			}
+		}
+	}
+	stateZip := strings.TrimSpace(candidate.State + " " + candidate.Zip5)
+	candidate.Address = strings.Join(nonEmpty(candidate.City, stateZip), ", ")
+	return []Candidate{candidate}, nil
+}
+
+// buildPlaceQuery finds the centroid of the building points in the ZIP codes.
+func buildPlaceQuery(zips []string) map[string]interface{} {
+	return map[string]interface{}{
+		"query": map[string]interface{}{
+			"bool": map[string]interface{}{
+				"filter":   map[string]interface{}{"terms": map[string]interface{}{"zip_5": zips}},
+				"must_not": map[string]interface{}{"exists": map[string]interface{}{"field": "unit_id"}},
+			},
+		},
+		"aggs": map[string]interface{}{
+			"centroid": map[string]interface{}{"geo_centroid": map[string]interface{}{"field": "lat_long"}},
+		},
+	}
+}
+
+// scorePlace scores a place on the parts the query has, the same as an address. The points are filtered to the ZIP
+// codes, so the ZIP code always matches, and a city given with a ZIP code matches when it is known by the ZIP code.
+func (s *Searcher) scorePlace(place parser.Place) float64 {
+	if place.City == "" || place.Zip5 == "" {
+		return 1
+	}
+	total, matched := zipWeight+cityWeight, zipWeight
+	if s.zipCities.Accepts(place.Zip5, place.City) {
+		matched += cityWeight
+	}
+	return matched / total
+}
@@ -0,0 +1,101 @@
+package api
+
+import (
+	"cook-county-geocoder/parser"
+	"cook-county-geocoder/shared/zipcity"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const stubCentroidResponse = `{
+  "hits": {"hits": []},
+  "aggregations": {"centroid": {"location": {"lat": 41.8745, "lon": -87.6601}, "count": 8421}}
+}`
+
+var placeZipCities = zipcity.Table{
+	"60607": {City: "CHICAGO", State: "IL"},
+	"60302": {City: "OAK PARK", State: "IL"},
+	"60304": {City: "OAK PARK", State: "IL"},
+}
+
+// newPlaceServer answers centroid queries with the centroid and any other search with the address stub.
+func newPlaceServer(t *testing.T) (*Server, *[]string) {
+	var bodies []string
+	server := newRoutingStubServer(t, func(body string) string {
+		bodies = append(bodies, body)
+		if strings.Contains(body, `"geo_centroid"`) {
+			return stubCentroidResponse
+		}
+		return stubSearchResponse
+	})
+	server.searcher.zipCities = placeZipCities
+	return server, &bodies
+}
+
+func TestGeocodeZipOnly(t *testing.T) {
+	server, bodies := newPlaceServer(t)
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/geocode?q=60607", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200. actual: %d body: %s", rec.Code, rec.Body.String())
+	}
+
+	var body GeocodeResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Could not decode response %s", err)
+	}
+	if len(body.Candidates) != 1 {
+		t.Fatalf("Expected the ZIP code centroid. actual: %v", body.Candidates)
+	}
+	best := body.Candidates[0]
+	if best.MatchType != MatchZip || best.Address != "CHICAGO, IL 60607" || best.Score != 1 || best.Latitude != 41.8745 || best.Longitude != -87.6601 {
+		t.Errorf("Unexpected ZIP code candidate %v", best)
+	}
+	if len(*bodies) != 1 || !strings.Contains((*bodies)[0], `"terms":{"zip_5":["60607"]}`) {
+		t.Errorf("Expected one centroid query filtered to the ZIP code. actual: %v", *bodies)
+	}
+}
+
+func TestGeocodeCityOnly(t *testing.T) {
+	server, bodies := newPlaceServer(t)
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/geocode?q=Oak+Park,+IL", nil))
+
+	var body GeocodeResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Could not decode response %s", err)
+	}
+	if len(body.Candidates) != 1 || body.Candidates[0].MatchType != MatchCity || body.Candidates[0].Address != "OAK PARK, IL" || body.Candidates[0].Zip5 != "" {
+		t.Fatalf("Expected the city centroid. actual: %v", body.Candidates)
+	}
+	if len(*bodies) != 1 || !strings.Contains((*bodies)[0], `"terms":{"zip_5":["60302","60304"]}`) {
+		t.Errorf("Expected one centroid query filtered to the ZIP codes of the city. actual: %v", *bodies)
+	}
+}
+
+func TestGeocodeUnknownCityIsAStreet(t *testing.T) {
+	server, _ := newPlaceServer(t)
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/geocode?q=Madison", nil))
+
+	var body GeocodeResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Could not decode response %s", err)
+	}
+	if len(body.Candidates) != 2 || body.Candidates[0].MatchType != MatchAddress {
+		t.Errorf("Expected a street search for a name that is not a city. actual: %v", body.Candidates)
+	}
+}
+
+func TestScorePlace(t *testing.T) {
+	searcher := &Searcher{zipCities: placeZipCities}
+	if score := searcher.scorePlace(parser.Place{City: "CHICAGO", Zip5: "60607"}); score != 1 {
+		t.Errorf("Expected the city of the ZIP code to match. score: %f", score)
+	}
+	if score := searcher.scorePlace(parser.Place{City: "OAK PARK", Zip5: "60607"}); score != 0.5 {
+		t.Errorf("Expected a city the ZIP code is not known by to cost its weight. score: %f", score)
+	}
+}
@@ -0,0 +1,28 @@
+package api
+
+import (
+	"cook-county-geocoder/shared/projection"
+	"strconv"
+	"strings"
+)
+
+// parseSpatialReference reads the sr parameter, the EPSG code of the coordinate system to return. Latitude and longitude
+// (4326) are always returned, and State Plane Illinois East (3435) adds x and y in feet. Both "3435" and "EPSG:3435"
+// are accepted.
+func parseSpatialReference(raw string) (int, error) {
+	if raw == "" {
+		return projection.WGS84, nil
+	}
+	code, err := strconv.Atoi(strings.TrimPrefix(strings.ToUpper(raw), "EPSG:"))
+	if err != nil || (code != projection.WGS84 && code != projection.IllinoisEastCode) {
+		return 0, &paramError{name: "sr", value: raw}
+	}
+	return code, nil
+}
+
+// project sets the State Plane coordinates of the result when they are requested.
+func (a *AddressResult) project(sr int) {
+	if sr == projection.IllinoisEastCode {
+		a.X, a.Y = projection.IllinoisEast.Forward(a.Latitude, a.Longitude)
+	}
+}
@@ -0,0 +1,50 @@
+package api
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseSpatialReference(t *testing.T) {
+	cases := map[string]int{"": 4326, "4326": 4326, "3435": 3435, "epsg:3435": 3435}
+	for raw, expected := range cases {
+		if actual, err := parseSpatialReference(raw); err != nil || actual != expected {
+			t.Errorf("Error parsing %q. actual: %d err: %v", raw, actual, err)
+		}
+	}
+	for _, raw := range []string{"3857", "wgs84"} {
+		if _, err := parseSpatialReference(raw); err == nil {
+			t.Errorf("Expected an error for %q", raw)
+		}
+	}
+}
+
+func TestGeocodeEndpointReturnsStatePlane(t *testing.T) {
+	server := newStubServer(t, stubSearchResponse)
+
+	for target, projected := range map[string]bool{"/geocode?q=1200+W+Madison+St&sr=3435": true, "/geocode?q=1200+W+Madison+St": false} {
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected status 200. actual: %d body: %s", rec.Code, rec.Body.String())
+		}
+		var body GeocodeResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+			t.Fatalf("Could not decode response %s", err)
+		}
+		best := body.Candidates[0]
+		if !projected {
+			if best.X != 0 || best.Y != 0 {
+				t.Errorf("Expected no State Plane coordinates unless requested. actual: %v", best)
+			}
+			continue
+		}
+		// 1200 W is a mile and a half, about 7900 feet, west of State Street at x 1176400.
+		if math.Abs(best.X-1168500) > 500 || math.Abs(best.Y-1900250) > 500 || best.Latitude != 41.8817 {
+			t.Errorf("Unexpected State Plane coordinates %f,%f", best.X, best.Y)
+		}
+	}
+}
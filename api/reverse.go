@@ -0,0 +1,60 @@
+package api
+
+import (
+	"context"
+	"fmt"
+)
+
+const (
+	DefaultRadiusMeters = 100.0
+	MaxRadiusMeters     = 5000.0
+)
+
+// Reverse returns up to size address points within radiusMeters of the location, nearest first.
+func (s *Searcher) Reverse(ctx context.Context, lat float64, lon float64, radiusMeters float64, size int) ([]ReverseCandidate, error) {
+	res, err := s.search(ctx, buildReverseQuery(lat, lon, radiusMeters), size)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make([]ReverseCandidate, 0, len(res.Hits.Hits))
+	for _, hit := range res.Hits.Hits {
+		if len(hit.Sort) == 0 {
+			return nil, fmt.Errorf("search hit %s is missing its sort distance", hit.Id)
+		}
+		distance, ok := hit.Sort[0].(float64)
+		if !ok {
+			return nil, fmt.Errorf("search hit %s has a non numeric sort distance %v", hit.Id, hit.Sort[0])
+		}
+		candidates = append(candidates, ReverseCandidate{AddressResult: toAddressResult(hit.Source), DistanceMeters: distance})
+	}
+	return candidates, nil
+}
+
+// buildReverseQuery filters address points to the radius and sorts them by arc distance in meters. The sort value of
+// each hit is the distance.
+func buildReverseQuery(lat float64, lon float64, radiusMeters float64) map[string]interface{} {
+	point := map[string]interface{}{"lat": lat, "lon": lon}
+	return map[string]interface{}{
+		"query": map[string]interface{}{
+			"bool": map[string]interface{}{
+				"filter": map[string]interface{}{
+					"geo_distance": map[string]interface{}{
+						"distance": fmt.Sprintf("%fm", radiusMeters),
+						"lat_long": point,
+					},
+				},
+			},
+		},
+		"sort": []interface{}{
+			map[string]interface{}{
+				"_geo_distance": map[string]interface{}{
+					"lat_long":      point,
+					"order":         "asc",
+					"unit":          "m",
+					"distance_type": "arc",
+				},
+			},
+		},
+	}
+}
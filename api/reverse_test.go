@@ -0,0 +1,77 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const stubReverseResponse = `{
+  "hits": {
+    "max_score": null,
+    "hits": [
+      {"_id": "a", "_score": null, "sort": [12.5], "_source": {"number": 1200, "street_prefix": "W", "street": "MADISON", "street_suffix": "ST", "city": "CHICAGO", "state": "IL", "zip_5": "60607", "lat_long": {"lat": 41.8817, "lon": -87.6579}}},
+      {"_id": "b", "_score": null, "sort": [40.25], "_source": {"number": 1202, "street_prefix": "W", "street": "MADISON", "street_suffix": "ST", "city": "CHICAGO", "state": "IL", "zip_5": "60607", "lat_long": {"lat": 41.8817, "lon": -87.6583}}}
+    ]
+  }
+}`
+
+func TestBuildReverseQuerySortsByDistance(t *testing.T) {
+	encoded, err := json.Marshal(buildReverseQuery(41.88, -87.65, 250))
+	if err != nil {
+		t.Fatalf("Could not encode query %s", err)
+	}
+	query := string(encoded)
+
+	for _, expected := range []string{`"distance":"250.000000m"`, `"_geo_distance"`, `"unit":"m"`, `"order":"asc"`} {
+		if !strings.Contains(query, expected) {
+			t.Errorf("Expected query to contain %s. query: %s", expected, query)
+		}
+	}
+}
+
+func TestReverseEndpoint(t *testing.T) {
+	server := newStubServer(t, stubReverseResponse)
+
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/reverse?lat=41.8817&lon=-87.658&radius=50&limit=2", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200. actual: %d body: %s", rec.Code, rec.Body.String())
+	}
+
+	var body ReverseResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Could not decode response %s", err)
+	}
+	if len(body.Candidates) != 2 {
+		t.Fatalf("Expected 2 candidates. actual: %d", len(body.Candidates))
+	}
+	if body.Candidates[0].DistanceMeters != 12.5 || body.Candidates[1].DistanceMeters != 40.25 {
+		t.Errorf("Expected distances from sort values. actual: %v", body.Candidates)
+	}
+}
+
+func TestReverseEndpointRejectsBadParameters(t *testing.T) {
+	server := newStubServer(t, stubReverseResponse)
+
+	targets := []string{
+		"/reverse?lon=-87.65",
+		"/reverse?lat=41.88",
+		"/reverse?lat=91&lon=-87.65",
+		"/reverse?lat=41.88&lon=-181",
+		"/reverse?lat=41.88&lon=-87.65&radius=0",
+		"/reverse?lat=41.88&lon=-87.65&radius=5001",
+		"/reverse?lat=NaN&lon=NaN",
+		"/reverse?lat=41.88&lon=-87.65&radius=NaN",
+		"/reverse?lat=Inf&lon=-87.65",
+	}
+	for _, target := range targets {
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400 for %s. actual: %d", target, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,336 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"cook-county-geocoder/parser"
+	"cook-county-geocoder/shared/mapping"
+	"cook-county-geocoder/shared/phonetic"
+	"cook-county-geocoder/shared/standardize"
+	"cook-county-geocoder/shared/zipcity"
+	"encoding/json"
+	"fmt"
+	"github.com/elastic/go-elasticsearch/v7"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Searcher runs geocoding queries against an address index. zipCities holds the cities each ZIP code is known by, and is
+// nil when the table is not available.
+type Searcher struct {
+	es        *elasticsearch.Client
+	indexName string
+	zipCities zipcity.Table
+}
+
+func NewSearcher(es *elasticsearch.Client, indexName string, zipCities zipcity.Table) *Searcher {
+	return &Searcher{es: es, indexName: indexName, zipCities: zipCities}
+}
+
+// Geocode parses a free text address, intersection, ZIP code or city and returns up to size candidates, best match
+// first.
+func (s *Searcher) Geocode(ctx context.Context, query string, size int) ([]Candidate, error) {
+	if intersection, ok := parser.ParseIntersection(query); ok {
+		return s.geocodeIntersection(ctx, intersection, size)
+	}
+
+	if place, ok := parser.ParsePlace(query); ok {
+		if zips := s.placeZips(place); len(zips) > 0 {
+			return s.geocodePlace(ctx, place, zips)
+		}
+	}
+
+	parsed, err := parser.Parse(query)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse query %q: %w", query, err)
+	}
+
+	// Fetch extra hits so candidates can be re-ranked by how well their parts match the query.
+	var cityZips []string
+	if parsed.City != "" {
+		cityZips = s.zipCities.Zips(parsed.City)
+	}
+	res, err := s.search(ctx, buildGeocodeQuery(parsed, cityZips), size+GeocodeOverFetch)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make([]Candidate, 0, len(res.Hits.Hits))
+	for _, hit := range res.Hits.Hits {
+		_, fuzzy := streetSimilarity(parsed.Street, hit.Source.Street)
+		candidates = append(candidates, Candidate{AddressResult: toAddressResult(hit.Source), Score: scoreCandidate(parsed, s.acceptCity(parsed, hit.Source)), Fuzzy: fuzzy, MatchType: MatchAddress})
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].Score > candidates[j].Score })
+
+	// The house number may not have an address point. Estimate it from the neighbors on the best matching street.
+	if parsed.Number > 0 && len(candidates) > 0 && !hasNumber(candidates, candidates[0].AddressResult, parsed.Number) {
+		interpolated, ok, err := s.interpolate(ctx, parsed, candidates[0].AddressResult)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			candidates = append(candidates, interpolated)
+			sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].Score > candidates[j].Score })
+		}
+	}
+
+	if len(candidates) > size {
+		candidates = candidates[:size]
+	}
+	return candidates, nil
+}
+
+// GeocodeOverFetch is the number of hits fetched beyond the requested size for re-ranking.
+const GeocodeOverFetch = 10
+
+// buildGeocodeQuery requires the street to match and boosts results matching the other parts of the parsed address.
+// The street matches exactly, within the fuzziness AUTO edit distance or by its phonetic key, with exact matches
+// boosted highest. A query with a unit boosts the unit level point and still finds the building point when the unit is
+// not indexed. A query without a unit boosts building points over the units inside them. cityZips are the ZIP codes
+// known by the query city, so a city that is not the mailing city of an address still matches it.
+func buildGeocodeQuery(parsed parser.ParsedAddress, cityZips []string) map[string]interface{} {
+	should := make([]interface{}, 0, 10)
+	if parsed.Number > 0 {
+		should = append(should, termClause("number", parsed.Number, 3))
+	}
+	// Half addresses, lettered addresses and ranges share the numeric part with the plain number.
+	if parsed.HouseNumber != "" && parsed.HouseNumber != strconv.Itoa(parsed.Number) {
+		should = append(should, termClause("house_number", parsed.HouseNumber, 2))
+	}
+	// The parser standardizes prefixes and suffixes. The alias clauses match documents indexed with long forms.
+	if parsed.StreetPrefix != "" {
+		should = append(should,
+			termClause("street_prefix", parsed.StreetPrefix, 1),
+			termClause("street_prefix_alias", standardize.DirectionalLongForm(parsed.StreetPrefix), 1),
+		)
+	}
+	if parsed.StreetSuffix != "" {
+		should = append(should,
+			matchClause("street_suffix", parsed.StreetSuffix, 1),
+			termClause("street_suffix_alias", standardize.SuffixLongForm(parsed.StreetSuffix), 1),
+		)
+	}
+	if parsed.City != "" {
+		should = append(should, matchClause("city", parsed.City, 1))
+	}
+	if len(cityZips) > 0 {
+		should = append(should, map[string]interface{}{
+			"terms": map[string]interface{}{"zip_5": cityZips, "boost": 1},
+		})
+	}
+	if parsed.Zip5 != "" {
+		should = append(should, termClause("zip_5", parsed.Zip5, 2))
+	}
+	if parsed.UnitId != "" {
+		should = append(should, termClause("unit_id", parsed.UnitId, 2))
+	} else {
+		should = append(should, map[string]interface{}{
+			"bool": map[string]interface{}{
+				"must_not": map[string]interface{}{"exists": map[string]interface{}{"field": "unit_id"}},
+				"boost":    1,
+			},
+		})
+	}
+
+	return map[string]interface{}{
+		"query": map[string]interface{}{
+			"bool": map[string]interface{}{
+				"must":   []interface{}{streetClause(parsed.Street)},
+				"should": should,
+			},
+		},
+	}
+}
+
+func streetClause(street string) map[string]interface{} {
+	return map[string]interface{}{
+		"bool": map[string]interface{}{
+			"should": []interface{}{
+				matchClause("street", street, 2),
+				map[string]interface{}{
+					"match": map[string]interface{}{
+						"street": map[string]interface{}{"query": street, "fuzziness": "AUTO", "prefix_length": 1, "boost": 1},
+					},
+				},
+				matchClause("street_phonetic", phonetic.Key(street), 1),
+			},
+			"minimum_should_match": 1,
+		},
+	}
+}
+
+// Weights of each address part when scoring a candidate. Parts missing from the query are left out of the score.
+const (
+	numberWeight = 0.3
+	streetWeight = 0.3
+	prefixWeight = 0.1
+	suffixWeight = 0.1
+	cityWeight   = 0.1
+	zipWeight    = 0.1
+	unitWeight   = 0.1
+)
+
+// scoreCandidate compares the parsed query with a candidate part by part and returns the weighted share of matching
+// parts, from 0 to 1. Unlike the Elasticsearch score it does not depend on the other hits, so it can be compared
+// across queries. Misspelled street names are penalized. A house number with the same numeric part but a different
+// fraction, letter or range, such as 1234 1/2 for 1234, gets half credit. The unit counts when either the query or the
+// candidate has one: the building point of a requested unit gets half credit and a unit the query did not ask for gets
+// none.
+func scoreCandidate(parsed parser.ParsedAddress, doc mapping.EsAddress) float64 {
+	similarity, _ := streetSimilarity(parsed.Street, doc.Street)
+	total, matched := streetWeight, streetWeight*similarity
+
+	score := func(weight float64, queryValue string, docValue string) {
+		if queryValue == "" {
+			return
+		}
+		total += weight
+		if queryValue == strings.ToUpper(docValue) {
+			matched += weight
+		}
+	}
+	if parsed.Number > 0 {
+		total += numberWeight
+		switch {
+		case houseNumber(parsed.HouseNumber, parsed.Number) == strings.ToUpper(houseNumber(doc.HouseNumber, doc.Number)):
+			matched += numberWeight
+		case parsed.Number == doc.Number:
+			matched += numberWeight / 2
+		}
+	}
+	score(prefixWeight, parsed.StreetPrefix, standardize.DirectionalOrOriginal(doc.StreetPrefix))
+	score(suffixWeight, parsed.StreetSuffix, standardize.SuffixOrOriginal(doc.StreetSuffix))
+	score(cityWeight, parsed.City, doc.City)
+	score(zipWeight, parsed.Zip5, doc.Zip5)
+
+	if parsed.UnitId != "" || doc.UnitId != "" {
+		total += unitWeight
+		switch {
+		case doc.UnitId == "":
+			matched += unitWeight / 2
+		case parsed.UnitId == strings.ToUpper(doc.UnitId):
+			matched += unitWeight
+		}
+	}
+
+	return matched / total
+}
+
+// acceptCity gives the candidate the query city when its ZIP code is known by that city, such as an acceptable city of
+// the ZIP code, so the city scores as a match. The candidate is returned unchanged without a ZIP code city table.
+func (s *Searcher) acceptCity(parsed parser.ParsedAddress, doc mapping.EsAddress) mapping.EsAddress {
+	if parsed.City != "" && doc.Zip5 != "" && s.zipCities != nil {
+		if _, ok := s.zipCities[doc.Zip5]; ok && s.zipCities.Accepts(doc.Zip5, parsed.City) {
+			doc.City = parsed.City
+		}
+	}
+	return doc
+}
+
+// houseNumber returns the house number as written, or the plain number when it was not recorded.
+func houseNumber(written string, number int) string {
+	if written == "" {
+		return strconv.Itoa(number)
+	}
+	return written
+}
+
+func matchClause(field string, text string, boost float64) map[string]interface{} {
+	return map[string]interface{}{
+		"match": map[string]interface{}{
+			field: map[string]interface{}{"query": text, "boost": boost},
+		},
+	}
+}
+
+func termClause(field string, value interface{}, boost float64) map[string]interface{} {
+	return map[string]interface{}{
+		"term": map[string]interface{}{
+			field: map[string]interface{}{"value": value, "boost": boost},
+		},
+	}
+}
+
+// Elasticsearch response structures. Only the fields used by the API are mapped.
+type esSearchResponse struct {
+	Hits struct {
+		Total struct {
+			Value int `json:"value"`
+		} `json:"total"`
+		MaxScore float64 `json:"max_score"`
+		Hits     []esHit `json:"hits"`
+	} `json:"hits"`
+	Aggregations json.RawMessage `json:"aggregations"`
+}
+
+type esHit struct {
+	Id     string            `json:"_id"`
+	Score  float64           `json:"_score"`
+	Source mapping.EsAddress `json:"_source"`
+	Sort   []interface{}     `json:"sort"`
+	// MatchedQueries holds the names of the named query clauses the hit matched.
+	MatchedQueries []string `json:"matched_queries"`
+}
+
+// search sends the query body to the address index and decodes the hits.
+func (s *Searcher) search(ctx context.Context, body map[string]interface{}, size int) (esSearchResponse, error) {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(body); err != nil {
+		return esSearchResponse{}, fmt.Errorf("could not encode search body: %w", err)
+	}
+
+	res, err := s.es.Search(
+		s.es.Search.WithContext(ctx),
+		s.es.Search.WithIndex(s.indexName),
+		s.es.Search.WithBody(&buf),
+		s.es.Search.WithSize(size),
+	)
+	if err != nil {
+		return esSearchResponse{}, fmt.Errorf("search request failed: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return esSearchResponse{}, fmt.Errorf("search response error: %s", res.String())
+	}
+
+	var parsed esSearchResponse
+	if err := json.NewDecoder(res.Body).Decode(&parsed); err != nil {
+		return esSearchResponse{}, fmt.Errorf("could not decode search response: %w", err)
+	}
+	return parsed, nil
+}
+
+func toAddressResult(doc mapping.EsAddress) AddressResult {
+	return AddressResult{
+		Address:        mapping.FormatAddress(doc),
+		Number:         doc.Number,
+		HouseNumber:    doc.HouseNumber,
+		StreetPrefix:   doc.StreetPrefix,
+		Street:         doc.Street,
+		StreetSuffix:   doc.StreetSuffix,
+		UnitDesignator: doc.UnitDesignator,
+		UnitId:         doc.UnitId,
+		City:           doc.City,
+		State:          doc.State,
+		Zip5:           doc.Zip5,
+		Latitude:       doc.LatLong.Latitude,
+		Longitude:      doc.LatLong.Longitude,
+		Municipality:   doc.Municipality,
+		Township:       doc.Township,
+		Ward:           doc.Ward,
+		CensusTract:    doc.CensusTract,
+		Pin:            doc.Pin,
+	}
+}
+
+func nonEmpty(values ...string) []string {
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		if v != "" {
+			result = append(result, v)
+		}
+	}
+	return result
+}
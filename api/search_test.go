@@ -1 +1,172 @@
 package api
+
+import (
+	"cook-county-geocoder/parser"
+	"cook-county-geocoder/shared/mapping"
+	"cook-county-geocoder/shared/zipcity"
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestBuildGeocodeQueryRequiresStreet(t *testing.T) {
+	parsed, err := parser.Parse("1200 W Madison St 60607")
+	if err != nil {
+		t.Fatalf("Could not parse query %s", err)
+	}
+	encoded, err := json.Marshal(buildGeocodeQuery(parsed, nil))
+	if err != nil {
+		t.Fatalf("Could not encode query %s", err)
+	}
+	query := string(encoded)
+
+	expectedClauses := []string{
+		`"must":[{"bool":{"minimum_should_match":1,"should":[{"match":{"street"`,
+		`"fuzziness":"AUTO"`,
+		`"match":{"street_phonetic":{"boost":1,"query":"M325"}}`,
+		`"term":{"number"`,
+		`"term":{"street_prefix"`,
+		`"term":{"street_prefix_alias":{"boost":1,"value":"WEST"}}`,
+		`"term":{"street_suffix_alias":{"boost":1,"value":"STREET"}}`,
+		`"term":{"zip_5"`,
+	}
+	for _, expected := range expectedClauses {
+		if !strings.Contains(query, expected) {
+			t.Errorf("Expected query to contain %s. query: %s", expected, query)
+		}
+	}
+	if strings.Contains(query, `"city"`) {
+		t.Errorf("Expected no city clause when the query has no city. query: %s", query)
+	}
+}
+
+func TestScoreCandidate(t *testing.T) {
+	parsed, err := parser.Parse("1200 W Madison St, Chicago 60607")
+	if err != nil {
+		t.Fatalf("Could not parse query %s", err)
+	}
+	doc := mapping.EsAddress{
+		Number:       1200,
+		StreetPrefix: "W",
+		Street:       "MADISON",
+		StreetSuffix: "ST",
+		City:         "CHICAGO",
+		State:        "IL",
+		Zip5:         "60607",
+	}
+	if score := scoreCandidate(parsed, doc); score != 1 {
+		t.Errorf("Expected a score of 1 when every part matches. actual: %f", score)
+	}
+
+	doc.StreetSuffix = "STREET"
+	if score := scoreCandidate(parsed, doc); score != 1 {
+		t.Errorf("Expected unstandardized suffixes to match. actual: %f", score)
+	}
+
+	doc.Number = 1202
+	doc.Zip5 = "60606"
+	if score := scoreCandidate(parsed, doc); math.Abs(score-0.6) > 1e-9 {
+		t.Errorf("Expected number and ZIP mismatches to cost their weights. actual: %f", score)
+	}
+}
+
+func TestScoreCandidateIgnoresPartsMissingFromQuery(t *testing.T) {
+	parsed, err := parser.Parse("Madison")
+	if err != nil {
+		t.Fatalf("Could not parse query %s", err)
+	}
+	doc := mapping.EsAddress{Number: 1200, StreetPrefix: "W", Street: "MADISON", StreetSuffix: "ST", Zip5: "60607"}
+	if score := scoreCandidate(parsed, doc); score != 1 {
+		t.Errorf("Expected a score of 1 when the only query part matches. actual: %f", score)
+	}
+}
+
+func TestBuildGeocodeQueryUnitClause(t *testing.T) {
+	parsed, err := parser.Parse("1200 W Madison St Apt 4B")
+	if err != nil {
+		t.Fatalf("Could not parse query %s", err)
+	}
+	encoded, _ := json.Marshal(buildGeocodeQuery(parsed, nil))
+	if !strings.Contains(string(encoded), `"term":{"unit_id":{"boost":2,"value":"4B"}}`) {
+		t.Errorf("Expected a unit clause. query: %s", encoded)
+	}
+
+	parsed, _ = parser.Parse("1200 W Madison St")
+	encoded, _ = json.Marshal(buildGeocodeQuery(parsed, nil))
+	if !strings.Contains(string(encoded), `"must_not":{"exists":{"field":"unit_id"}}`) {
+		t.Errorf("Expected building points to be boosted without a unit. query: %s", encoded)
+	}
+}
+
+func TestScoreCandidateWithUnits(t *testing.T) {
+	parsed, err := parser.Parse("1200 W Madison St Apt 4B")
+	if err != nil {
+		t.Fatalf("Could not parse query %s", err)
+	}
+	doc := mapping.EsAddress{Number: 1200, StreetPrefix: "W", Street: "MADISON", StreetSuffix: "ST", UnitDesignator: "APT", UnitId: "4B"}
+	unit := scoreCandidate(parsed, doc)
+	if unit != 1 {
+		t.Errorf("Expected a score of 1 for the requested unit. actual: %f", unit)
+	}
+
+	doc.UnitDesignator, doc.UnitId = "", ""
+	building := scoreCandidate(parsed, doc)
+	doc.UnitDesignator, doc.UnitId = "APT", "5C"
+	otherUnit := scoreCandidate(parsed, doc)
+	if !(unit > building && building > otherUnit) {
+		t.Errorf("Expected the building point to rank between the unit and another unit. actual: %f %f %f", unit, building, otherUnit)
+	}
+
+	parsed, _ = parser.Parse("1200 W Madison St")
+	if scoreCandidate(parsed, doc) >= 1 {
+		t.Errorf("Expected a unit point to score below the building point when no unit was asked for.")
+	}
+}
+
+func TestScoreCandidateMatchesHouseNumberExactly(t *testing.T) {
+	parsed, err := parser.Parse("1234 1/2 S Halsted St")
+	if err != nil {
+		t.Fatalf("Could not parse query %s", err)
+	}
+	doc := mapping.EsAddress{Number: 1234, HouseNumber: "1234 1/2", NumberFraction: "1/2", StreetPrefix: "S", Street: "HALSTED", StreetSuffix: "ST"}
+	half := scoreCandidate(parsed, doc)
+	doc.HouseNumber, doc.NumberFraction = "1234", ""
+	plain := scoreCandidate(parsed, doc)
+	if half != 1 || !(plain < half) || plain <= 0.8 {
+		t.Errorf("Expected the half address to beat the plain number, which keeps half credit. actual: %f %f", half, plain)
+	}
+
+	encoded, _ := json.Marshal(buildGeocodeQuery(parsed, nil))
+	if !strings.Contains(string(encoded), `"term":{"house_number":{"boost":2,"value":"1234 1/2"}}`) {
+		t.Errorf("Expected a house number clause. query: %s", encoded)
+	}
+}
+
+func TestGeocodeMatchesCitiesKnownByZip(t *testing.T) {
+	zipCities := zipcity.Table{"60453": {City: "OAK LAWN", State: "IL", Acceptable: []string{"HOMETOWN"}}}
+	searcher := &Searcher{zipCities: zipCities}
+
+	parsed, err := parser.Parse("4300 W 95th St, Hometown")
+	if err != nil {
+		t.Fatalf("Could not parse query %s", err)
+	}
+	encoded, _ := json.Marshal(buildGeocodeQuery(parsed, zipCities.Zips(parsed.City)))
+	if !strings.Contains(string(encoded), `"terms":{"boost":1,"zip_5":["60453"]}`) {
+		t.Errorf("Expected a clause for the ZIP codes of the city. query: %s", encoded)
+	}
+
+	doc := mapping.EsAddress{Number: 4300, StreetPrefix: "W", Street: "95TH", StreetSuffix: "ST", City: "OAK LAWN", Zip5: "60453"}
+	if score := scoreCandidate(parsed, searcher.acceptCity(parsed, doc)); score != 1 {
+		t.Errorf("Expected an acceptable city of the ZIP code to match. score: %f", score)
+	}
+	if score := scoreCandidate(parsed, (&Searcher{}).acceptCity(parsed, doc)); score >= 1 {
+		t.Errorf("Expected the city to only match by name without a table. score: %f", score)
+	}
+
+	// A query with only a ZIP code is scored on the parts it has.
+	parsed, _ = parser.Parse("4300 W 95th St 60453")
+	if score := scoreCandidate(parsed, searcher.acceptCity(parsed, doc)); score != 1 {
+		t.Errorf("Expected a ZIP code only query to match. score: %f", score)
+	}
+}
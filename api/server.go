@@ -0,0 +1,291 @@
+package api
+
+import (
+	"cook-county-geocoder/parser"
+	"cook-county-geocoder/shared/grid"
+	"encoding/json"
+	"errors"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	DefaultLimit = 5
+	MaxLimit     = 50
+)
+
+// Server exposes the Searcher over HTTP. The grid endpoint is only available when a grid model is given.
+type Server struct {
+	searcher *Searcher
+	grid     *grid.Model
+	mux      *http.ServeMux
+}
+
+func NewServer(searcher *Searcher, gridModel *grid.Model) *Server {
+	s := &Server{searcher: searcher, grid: gridModel, mux: http.NewServeMux()}
+	s.mux.HandleFunc("/geocode", s.handleGeocode)
+	s.mux.HandleFunc("/reverse", s.handleReverse)
+	s.mux.HandleFunc("/autocomplete", s.handleAutocomplete)
+	s.mux.HandleFunc("/batch", s.handleBatch)
+	s.mux.HandleFunc("/grid", s.handleGrid)
+	s.mux.HandleFunc("/parcel", s.handleParcel)
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// handleGeocode serves GET /geocode?q=<free text address>&limit=<n>&sr=<4326|3435>
+func (s *Server) handleGeocode(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		writeError(w, http.StatusBadRequest, "missing required parameter q")
+		return
+	}
+	limit, err := parseLimit(r.URL.Query().Get("limit"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	sr, err := parseSpatialReference(r.URL.Query().Get("sr"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	candidates, err := s.searcher.Geocode(r.Context(), query, limit)
+	if errors.Is(err, parser.ErrEmptyAddress) || errors.Is(err, parser.ErrNoStreet) {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		log.Printf("Error geocoding query %q: %s\n", query, err)
+		writeError(w, http.StatusBadGateway, "error searching address index")
+		return
+	}
+	for i := range candidates {
+		candidates[i].project(sr)
+	}
+	writeJSON(w, http.StatusOK, GeocodeResponse{Query: query, Candidates: candidates})
+}
+
+// handleReverse serves GET /reverse?lat=<lat>&lon=<lon>&radius=<meters>&limit=<n>&sr=<4326|3435>
+func (s *Server) handleReverse(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	params := r.URL.Query()
+	lat, err := parseFloatParam("lat", params.Get("lat"), -90, 90)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	lon, err := parseFloatParam("lon", params.Get("lon"), -180, 180)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	radius := DefaultRadiusMeters
+	if raw := params.Get("radius"); raw != "" {
+		radius, err = parseFloatParam("radius", raw, 1, MaxRadiusMeters)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	limit, err := parseLimit(params.Get("limit"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	sr, err := parseSpatialReference(params.Get("sr"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	candidates, err := s.searcher.Reverse(r.Context(), lat, lon, radius, limit)
+	if err != nil {
+		log.Printf("Error reverse geocoding %f,%f: %s\n", lat, lon, err)
+		writeError(w, http.StatusBadGateway, "error searching address index")
+		return
+	}
+	for i := range candidates {
+		candidates[i].project(sr)
+	}
+	writeJSON(w, http.StatusOK, ReverseResponse{Latitude: lat, Longitude: lon, Candidates: candidates})
+}
+
+// handleAutocomplete serves GET /autocomplete?q=<partial address>&limit=<n>&sr=<4326|3435>
+func (s *Server) handleAutocomplete(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		writeError(w, http.StatusBadRequest, "missing required parameter q")
+		return
+	}
+	limit, err := parseLimit(r.URL.Query().Get("limit"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	sr, err := parseSpatialReference(r.URL.Query().Get("sr"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	completions, err := s.searcher.Autocomplete(r.Context(), query, limit)
+	if err != nil {
+		log.Printf("Error autocompleting query %q: %s\n", query, err)
+		writeError(w, http.StatusBadGateway, "error searching address index")
+		return
+	}
+	for i := range completions {
+		completions[i].project(sr)
+	}
+	writeJSON(w, http.StatusOK, AutocompleteResponse{Query: query, Completions: completions})
+}
+
+// handleParcel serves GET /parcel?pin=<10 or 14 digit PIN>&sr=<4326|3435>
+func (s *Server) handleParcel(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	raw := strings.TrimSpace(r.URL.Query().Get("pin"))
+	if raw == "" {
+		writeError(w, http.StatusBadRequest, "missing required parameter pin")
+		return
+	}
+	pin, err := parsePin(raw)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	sr, err := parseSpatialReference(r.URL.Query().Get("sr"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	addresses, total, err := s.searcher.Parcel(r.Context(), pin)
+	if err != nil {
+		log.Printf("Error looking up parcel %s: %s\n", pin, err)
+		writeError(w, http.StatusBadGateway, "error searching address index")
+		return
+	}
+	for i := range addresses {
+		addresses[i].project(sr)
+	}
+	writeJSON(w, http.StatusOK, ParcelResponse{Pin: pin, Addresses: addresses, Total: total, Truncated: total > len(addresses)})
+}
+
+// handleBatch serves POST /batch?address_col=<name>&city_col=<name>&zip_col=<name> with a CSV request body. The
+// response is the same CSV with geocoding result columns appended.
+func (s *Server) handleBatch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	params := r.URL.Query()
+	columns := BatchColumns{
+		Address: params.Get("address_col"),
+		City:    params.Get("city_col"),
+		Zip:     params.Get("zip_col"),
+	}
+	if columns.Address == "" {
+		columns.Address = "address"
+	}
+
+	// Rows are streamed back as they are geocoded, so errors after the output has started can only be logged.
+	w.Header().Set("Content-Type", "text/csv")
+	out := &streamWriter{ResponseWriter: w}
+	stats, err := s.searcher.GeocodeCsv(r.Context(), r.Body, out, columns)
+	if err != nil && !out.started {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		log.Printf("Batch stopped after %d rows: %s\n", stats.Rows, err)
+		return
+	}
+	log.Printf("Batch geocoded %d rows: %+v\n", stats.Rows, stats)
+}
+
+// streamWriter records whether any of the response body has been sent.
+type streamWriter struct {
+	http.ResponseWriter
+	started bool
+}
+
+func (sw *streamWriter) Write(b []byte) (int, error) {
+	sw.started = true
+	return sw.ResponseWriter.Write(b)
+}
+
+func (sw *streamWriter) Flush() {
+	if flusher, ok := sw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// parseLimit defaults an empty limit and rejects anything outside of 1 to MaxLimit.
+func parseLimit(raw string) (int, error) {
+	if raw == "" {
+		return DefaultLimit, nil
+	}
+	limit, err := strconv.Atoi(raw)
+	if err != nil || limit < 1 || limit > MaxLimit {
+		return 0, &paramError{name: "limit", value: raw}
+	}
+	return limit, nil
+}
+
+// parseFloatParam requires a value between min and max, inclusive. NaN and infinities are rejected, since NaN compares
+// false with every bound.
+func parseFloatParam(name string, raw string, min float64, max float64) (float64, error) {
+	value, err := strconv.ParseFloat(raw, 64)
+	if err != nil || math.IsNaN(value) || math.IsInf(value, 0) || value < min || value > max {
+		return 0, &paramError{name: name, value: raw}
+	}
+	return value, nil
+}
+
+type paramError struct {
+	name  string
+	value string
+}
+
+func (e *paramError) Error() string {
+	return "invalid value for parameter " + e.name + ": " + e.value
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		log.Printf("Error writing response: %s\n", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, ErrorResponse{Error: message})
+}
@@ -0,0 +1,130 @@
+package api
+
+import (
+	"encoding/json"
+	"github.com/elastic/go-elasticsearch/v7"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const stubSearchResponse = `{
+  "hits": {
+    "max_score": 8.0,
+    "hits": [
+      {"_id": "a", "_score": 8.0, "_source": {"number": 1200, "street_prefix": "W", "street": "MADISON", "street_suffix": "ST", "city": "CHICAGO", "state": "IL", "zip_5": "60607", "lat_long": {"lat": 41.8817, "lon": -87.6579}, "municipality": "CHICAGO", "ward": "27"}},
+      {"_id": "b", "_score": 4.0, "_source": {"number": 1200, "street_prefix": "E", "street": "MADISON", "street_suffix": "ST", "city": "CHICAGO", "state": "IL", "zip_5": "60602", "lat_long": {"lat": 41.8819, "lon": -87.6201}}}
+    ]
+  }
+}`
+
+func TestGeocodeEndpoint(t *testing.T) {
+	server := newStubServer(t, stubSearchResponse)
+
+	req := httptest.NewRequest(http.MethodGet, "/geocode?q=1200+W+Madison+St+60607", nil)
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200. actual: %d body: %s", rec.Code, rec.Body.String())
+	}
+
+	var body GeocodeResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Could not decode response %s", err)
+	}
+	if len(body.Candidates) != 2 {
+		t.Fatalf("Expected 2 candidates. actual: %d", len(body.Candidates))
+	}
+
+	best := body.Candidates[0]
+	if best.Address != "1200 W MADISON ST, CHICAGO, IL 60607" || best.Score != 1 || best.Latitude != 41.8817 {
+		t.Errorf("Unexpected best candidate %v", best)
+	}
+	if best.Municipality != "CHICAGO" || best.Ward != "27" || best.Township != "" {
+		t.Errorf("Expected the areas of the best candidate. actual: %v", best)
+	}
+	// The second candidate has the wrong prefix and ZIP.
+	if math.Abs(body.Candidates[1].Score-0.7/0.9) > 1e-9 {
+		t.Errorf("Expected second candidate to lose the prefix and ZIP weights. actual: %f", body.Candidates[1].Score)
+	}
+}
+
+func TestGeocodeEndpointRejectsBadParameters(t *testing.T) {
+	server := newStubServer(t, stubSearchResponse)
+
+	for _, target := range []string{"/geocode", "/geocode?q=", "/geocode?q=madison&limit=0", "/geocode?q=madison&limit=abc", "/geocode?q=madison&sr=3857"} {
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400 for %s. actual: %d", target, rec.Code)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/geocode?q=madison", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected status 405 for POST. actual: %d", rec.Code)
+	}
+}
+
+func TestGeocodeEndpointRejectsUnparseableQueries(t *testing.T) {
+	server := newStubServer(t, stubSearchResponse)
+
+	for _, target := range []string{"/geocode?q=1234", "/geocode?q=...", "/geocode?q=1234+IL"} {
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400 for %s. actual: %d", target, rec.Code)
+		}
+		var body ErrorResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil || !strings.Contains(body.Error, "could not parse query") {
+			t.Errorf("Expected the parse error for %s. actual: %s", target, rec.Body.String())
+		}
+	}
+}
+
+func TestGeocodeEndpointSearchFailure(t *testing.T) {
+	stub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte(`{"error": {"type": "search_phase_execution_exception", "reason": "all shards failed"}}`))
+	}))
+	t.Cleanup(stub.Close)
+	es, err := elasticsearch.NewClient(elasticsearch.Config{Addresses: []string{stub.URL}})
+	if err != nil {
+		t.Fatalf("Could not build ES client %s", err)
+	}
+	server := NewServer(NewSearcher(es, "address", nil), nil)
+
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/geocode?q=1200+W+Madison+St", nil))
+	if rec.Code != http.StatusBadGateway {
+		t.Errorf("Expected status 502 when the search fails. actual: %d body: %s", rec.Code, rec.Body.String())
+	}
+}
+
+// newStubServer builds a Server backed by a fake Elasticsearch that answers every request with the given body.
+func newStubServer(t *testing.T, responseBody string) *Server {
+	return newRoutingStubServer(t, func(string) string { return responseBody })
+}
+
+// newRoutingStubServer builds a Server backed by a fake Elasticsearch that answers each request with the body chosen
+// from the request body.
+func newRoutingStubServer(t *testing.T, respond func(body string) string) *Server {
+	stub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(respond(string(body))))
+	}))
+	t.Cleanup(stub.Close)
+
+	es, err := elasticsearch.NewClient(elasticsearch.Config{Addresses: []string{stub.URL}})
+	if err != nil {
+		t.Fatalf("Could not build ES client %s", err)
+	}
+	return NewServer(NewSearcher(es, "address", nil), nil)
+}
@@ -0,0 +1,195 @@
+package data
+
+import (
+	"bytes"
+	"cook-county-geocoder/shared/zipcity"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+)
+
+// ArcGIS REST sources. The county publishes the address points as a MapServer layer whose query endpoint returns pages
+// of features as Esri JSON (f=json) or GeoJSON (f=geojson). Each feature becomes a row with a column for each attribute
+// and the point geometry in the geometry_x and geometry_y columns, so the same schemas map them to RawData.
+
+const (
+	// ArcGisPageSize is the number of features requested per page. Servers may return fewer, up to their own limit.
+	ArcGisPageSize = 1000
+
+	// Columns holding the point geometry of each feature, longitude and latitude with outSR=4326.
+	GeometryXColumn = "geometry_x"
+	GeometryYColumn = "geometry_y"
+)
+
+// ArcGisReader pages through an ArcGIS REST layer query and streams each valid feature to normalizedOutput and each
+// rejected feature to rejectedOutput. queryUrl is the layer query endpoint. Parameters already in it, such as where or
+// outFields, are kept, and missing ones default to every feature and field in WGS84. Rejected rows are numbered by
+// feature, starting at 1. Both channels are closed when the reader returns. A RequestError is returned if a page
+// cannot be fetched and a HeaderError if the features are missing fields the schema requires. Features outside the
+// boundary are rejected and cities checked against zipCities as in CsvReader.
+func ArcGisReader(client *http.Client, queryUrl string, schema Schema, boundary *Boundary, zipCities zipcity.Table, normalizedOutput chan<- Address, rejectedOutput chan<- RejectedRow) error {
+	defer close(normalizedOutput)
+	defer close(rejectedOutput)
+
+	base, err := url.Parse(queryUrl)
+	if err != nil {
+		return &RequestError{Url: queryUrl, Err: err}
+	}
+	params := base.Query()
+	for name, value := range map[string]string{"where": "1=1", "outFields": "*", "outSR": "4326", "f": "json"} {
+		if params.Get(name) == "" {
+			params.Set(name, value)
+		}
+	}
+	params.Set("resultRecordCount", strconv.Itoa(ArcGisPageSize))
+
+	normalizedAddressCount := 0
+	errorCount := 0
+	offset := 0
+	for {
+		params.Set("resultOffset", strconv.Itoa(offset))
+		base.RawQuery = params.Encode()
+		page, err := fetchArcGisPage(client, base.String())
+		if err != nil {
+			return err
+		}
+		if len(page.Features) == 0 {
+			break
+		}
+
+		header, rows := page.rows()
+		columns, err := schema.Resolve(header)
+		if err != nil {
+			return err
+		}
+		for i, row := range rows {
+			address, err := validateRaw(columns.Raw(row), boundary, zipCities)
+			if err != nil {
+				rejectedOutput <- newRejectedRow(offset+i+1, row, err)
+				errorCount++
+				continue
+			}
+			normalizedAddressCount++
+			normalizedOutput <- address
+		}
+
+		offset += len(page.Features)
+		if !page.ExceededTransferLimit && !page.Properties.ExceededTransferLimit {
+			break
+		}
+	}
+
+	log.Printf("Finished writing %d addresses to output channel\n", normalizedAddressCount)
+	log.Printf("Total errors: %d\n", errorCount)
+	return nil
+}
+
+// arcGisPage is a page of query results in either format. Esri JSON features have attributes and an x, y geometry, and
+// GeoJSON features have properties and a coordinates geometry. Esri JSON flags more pages at the top level and ArcGIS
+// GeoJSON in the top level properties.
+type arcGisPage struct {
+	Features []struct {
+		Attributes map[string]interface{} `json:"attributes"`
+		Properties map[string]interface{} `json:"properties"`
+		Geometry   *struct {
+			X           *json.Number  `json:"x"`
+			Y           *json.Number  `json:"y"`
+			Coordinates []json.Number `json:"coordinates"`
+		} `json:"geometry"`
+	} `json:"features"`
+	ExceededTransferLimit bool `json:"exceededTransferLimit"`
+	Properties            struct {
+		ExceededTransferLimit bool `json:"exceededTransferLimit"`
+	} `json:"properties"`
+	Error *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func fetchArcGisPage(client *http.Client, pageUrl string) (arcGisPage, error) {
+	res, err := client.Get(pageUrl)
+	if err != nil {
+		return arcGisPage{}, &RequestError{Url: pageUrl, Err: err}
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return arcGisPage{}, &RequestError{Url: pageUrl, StatusCode: res.StatusCode, Err: fmt.Errorf("unexpected status %s", res.Status)}
+	}
+
+	var page arcGisPage
+	decoder := json.NewDecoder(res.Body)
+	decoder.UseNumber()
+	if err := decoder.Decode(&page); err != nil {
+		return arcGisPage{}, &RequestError{Url: pageUrl, StatusCode: res.StatusCode, Err: err}
+	}
+	// Query errors are returned with a 200 status and an error body.
+	if page.Error != nil {
+		return arcGisPage{}, &RequestError{Url: pageUrl, StatusCode: page.Error.Code, Err: fmt.Errorf("%s", page.Error.Message)}
+	}
+	return page, nil
+}
+
+// rows flattens the features into a header of every attribute name, sorted, followed by the geometry columns, and one
+// row of values per feature. Missing and null values are empty.
+func (p arcGisPage) rows() ([]string, [][]string) {
+	names := make(map[string]bool)
+	for _, feature := range p.Features {
+		for name := range feature.Attributes {
+			names[name] = true
+		}
+		for name := range feature.Properties {
+			names[name] = true
+		}
+	}
+	header := make([]string, 0, len(names)+2)
+	for name := range names {
+		header = append(header, name)
+	}
+	sort.Strings(header)
+	header = append(header, GeometryXColumn, GeometryYColumn)
+
+	rows := make([][]string, 0, len(p.Features))
+	for _, feature := range p.Features {
+		attributes := feature.Attributes
+		if attributes == nil {
+			attributes = feature.Properties
+		}
+		row := make([]string, len(header))
+		for i, name := range header[:len(header)-2] {
+			row[i] = attributeString(attributes[name])
+		}
+		if g := feature.Geometry; g != nil {
+			switch {
+			case g.X != nil && g.Y != nil:
+				row[len(header)-2], row[len(header)-1] = g.X.String(), g.Y.String()
+			case len(g.Coordinates) >= 2:
+				row[len(header)-2], row[len(header)-1] = g.Coordinates[0].String(), g.Coordinates[1].String()
+			}
+		}
+		rows = append(rows, row)
+	}
+	return header, rows
+}
+
+// attributeString writes an attribute value the way it would appear in a CSV export.
+func attributeString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	default:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return ""
+		}
+		return string(bytes.Trim(encoded, `"`))
+	}
+}
@@ -0,0 +1,128 @@
+package data
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// newArcGisStub serves the recorded query responses in testdata, picking the page by resultOffset, and records the
+// query parameters of each request.
+func newArcGisStub(t *testing.T, pages map[string]string) (*httptest.Server, *[]url.Values) {
+	requests := make([]url.Values, 0)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.Query())
+		fixture, ok := pages[r.URL.Query().Get("resultOffset")]
+		if !ok {
+			http.Error(w, "unexpected offset", http.StatusBadRequest)
+			return
+		}
+		body, err := os.ReadFile(filepath.Join("testdata", fixture))
+		if err != nil {
+			t.Errorf("Could not read fixture %s", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(body)
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func arcGisSchema(t *testing.T) Schema {
+	schema, err := LoadSchema("schemas/cook_county_arcgis.json")
+	if err != nil {
+		t.Fatalf("Could not load the ArcGIS schema %s", err)
+	}
+	return schema
+}
+
+func TestArcGisReaderPagesThroughFeatures(t *testing.T) {
+	server, requests := newArcGisStub(t, map[string]string{"0": "arcgis_page_1.json", "2": "arcgis_page_2.json"})
+
+	normalized := make(chan Address, 10)
+	rejected := make(chan RejectedRow, 10)
+	err := ArcGisReader(server.Client(), server.URL+"/arcgis/rest/services/addressZipCode/MapServer/0/query?where=ZIP5%3D60607", arcGisSchema(t), nil, nil, normalized, rejected)
+	if err != nil {
+		t.Fatalf("Expected no errors reading features. Found %v", err)
+	}
+
+	if len(*requests) != 2 {
+		t.Fatalf("Expected two page requests. actual: %v", *requests)
+	}
+	first := (*requests)[0]
+	if first.Get("where") != "ZIP5=60607" || first.Get("outSR") != "4326" || first.Get("f") != "json" || first.Get("resultRecordCount") != "1000" {
+		t.Errorf("Unexpected query parameters %v", first)
+	}
+
+	addresses := make([]Address, 0)
+	for address := range normalized {
+		addresses = append(addresses, address)
+	}
+	if len(addresses) != 3 {
+		t.Fatalf("Expected 3 addresses. actual: %v", addresses)
+	}
+	if addresses[0].Number != 1200 || addresses[0].Zip5 != "60607" || addresses[0].Longitude != -87.65812 || addresses[0].Latitude != 41.88166 {
+		t.Errorf("Unexpected address %v", addresses[0])
+	}
+	if addresses[1].ZipLast4 != "1234" || addresses[2].HouseNumber != "1204 1/2" {
+		t.Errorf("Unexpected addresses %v", addresses[1:])
+	}
+
+	row := <-rejected
+	if row.Line != 4 || row.Category != CategoryMissingField {
+		t.Errorf("Expected the fourth feature to be rejected for missing fields. actual: %v", row)
+	}
+}
+
+func TestArcGisReaderReadsGeoJson(t *testing.T) {
+	server, requests := newArcGisStub(t, map[string]string{"0": "arcgis_geojson.json"})
+
+	normalized := make(chan Address, 10)
+	err := ArcGisReader(server.Client(), server.URL+"/query?f=geojson", arcGisSchema(t), nil, nil, normalized, make(chan RejectedRow, 10))
+	if err != nil {
+		t.Fatalf("Expected no errors reading features. Found %v", err)
+	}
+	if len(*requests) != 1 || (*requests)[0].Get("f") != "geojson" {
+		t.Errorf("Expected a single GeoJSON request. actual: %v", *requests)
+	}
+	address := <-normalized
+	if address.Street != "STATE" || address.Longitude != -87.6279 || address.Latitude != 41.8818 {
+		t.Errorf("Unexpected address %v", address)
+	}
+}
+
+func TestArcGisReaderReturnsRequestErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"error": {"code": 400, "message": "Invalid or missing input parameters.", "details": []}}`))
+	}))
+	defer server.Close()
+
+	normalized := make(chan Address, 10)
+	err := ArcGisReader(server.Client(), server.URL+"/query", arcGisSchema(t), nil, nil, normalized, make(chan RejectedRow, 10))
+	var requestErr *RequestError
+	if !errors.As(err, &requestErr) || requestErr.StatusCode != 400 {
+		t.Errorf("Expected a RequestError for an error body. actual: %v", err)
+	}
+	if _, open := <-normalized; open {
+		t.Errorf("Expected output channel to be closed.")
+	}
+
+	stub, _ := newArcGisStub(t, map[string]string{})
+	err = ArcGisReader(stub.Client(), stub.URL+"/query", arcGisSchema(t), nil, nil, make(chan Address, 10), make(chan RejectedRow, 10))
+	if !errors.As(err, &requestErr) || requestErr.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected a RequestError for an error status. actual: %v", err)
+	}
+}
+
+func TestArcGisReaderWithMissingFields(t *testing.T) {
+	server, _ := newArcGisStub(t, map[string]string{"0": "arcgis_geojson.json"})
+	err := ArcGisReader(server.Client(), server.URL+"/query", cookCountySchema(t), nil, nil, make(chan Address, 10), make(chan RejectedRow, 10))
+	var headerErr *HeaderError
+	if !errors.As(err, &headerErr) {
+		t.Errorf("Expected a HeaderError for features without the CSV coordinate columns. actual: %v", err)
+	}
+}
@@ -0,0 +1,100 @@
+package data
+
+import (
+	"cook-county-geocoder/shared/geo"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Area layers tag each address with the areas containing it, such as its municipality, which often differs from the
+// USPS mailing city. The Cook County layers are listed in data/boundaries/areas.json.
+
+// AreaLayer is one kind of area read from a GeoJSON file. Field is the Address field it fills and Property the feature
+// property holding the name of each area. Transforms are schema transforms applied in order to the name. File is
+// relative to the layer list. Areas are spatially indexed, since layers such as parcels hold millions of areas.
+type AreaLayer struct {
+	Field      string         `json:"field"`
+	File       string         `json:"file"`
+	Property   string         `json:"property"`
+	Transforms []string       `json:"transforms,omitempty"`
+	Areas      *geo.AreaIndex `json:"-"`
+}
+
+// AreaLayers are the layers an address is tagged with.
+type AreaLayers []AreaLayer
+
+// areaFields are the Address fields a layer can fill.
+var areaFields = map[string]func(*Address) *string{
+	"municipality": func(a *Address) *string { return &a.Municipality },
+	"township":     func(a *Address) *string { return &a.Township },
+	"ward":         func(a *Address) *string { return &a.Ward },
+	"census_tract": func(a *Address) *string { return &a.CensusTract },
+	"pin":          func(a *Address) *string { return &a.Pin },
+}
+
+// LoadAreaLayers reads a layer list and the GeoJSON file of each layer. Layers whose file does not exist are skipped, so
+// the list can name every layer while only some have been downloaded. A FileError is returned if the list or a layer
+// file cannot be read.
+func LoadAreaLayers(path string) (AreaLayers, error) {
+	file, err := os.ReadFile(path)
+	if err != nil {
+		return nil, &FileError{Path: path, Err: err}
+	}
+	var list struct {
+		Layers []AreaLayer `json:"layers"`
+	}
+	if err := json.Unmarshal(file, &list); err != nil {
+		return nil, &FileError{Path: path, Err: err}
+	}
+
+	layers := make(AreaLayers, 0, len(list.Layers))
+	for _, layer := range list.Layers {
+		if _, ok := areaFields[layer.Field]; !ok {
+			return nil, &FileError{Path: path, Err: fmt.Errorf("unknown area field %q", layer.Field)}
+		}
+		if layer.Property == "" {
+			return nil, &FileError{Path: path, Err: fmt.Errorf("area layer %s has no property", layer.Field)}
+		}
+		for _, name := range layer.Transforms {
+			if _, ok := transforms[name]; !ok {
+				return nil, &FileError{Path: path, Err: fmt.Errorf("area layer %s has unknown transform %q", layer.Field, name)}
+			}
+		}
+		layerFile := filepath.Join(filepath.Dir(path), layer.File)
+		areas, err := geo.LoadAreas(layerFile)
+		if errors.Is(err, os.ErrNotExist) {
+			log.Printf("No %s boundaries at %s, addresses are not tagged with a %s\n", layer.Field, layerFile, layer.Field)
+			continue
+		}
+		if err != nil {
+			return nil, &FileError{Path: layerFile, Err: err}
+		}
+		layer.Areas = geo.NewAreaIndex(areas)
+		layers = append(layers, layer)
+	}
+	return layers, nil
+}
+
+// Tag sets the field of each layer to the name of the first area containing the address, upper cased. Fields are left
+// empty for addresses outside every area of a layer, and fields already set, such as a PIN from the source, are kept.
+func (l AreaLayers) Tag(address Address) Address {
+	for _, layer := range l {
+		field := areaFields[layer.Field](&address)
+		if *field != "" {
+			continue
+		}
+		if area, ok := layer.Areas.Find(address.Latitude, address.Longitude); ok {
+			value := strings.ToUpper(area.Property(layer.Property))
+			for _, name := range layer.Transforms {
+				value = transforms[name](value)
+			}
+			*field = value
+		}
+	}
+	return address
+}
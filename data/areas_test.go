@@ -0,0 +1,100 @@
+package data
+
+import (
+	"cook-county-geocoder/shared/geo"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadAreaLayersSkipsMissingFiles(t *testing.T) {
+	layers, err := LoadAreaLayers("testdata/areas/areas.json")
+	if err != nil {
+		t.Fatalf("Expected no errors loading area layers. Found %v", err)
+	}
+	if len(layers) != 3 || layers[0].Field != "municipality" || layers[0].Areas.Len() != 2 || layers[1].Field != "ward" || layers[2].Field != "pin" {
+		t.Errorf("Expected the municipality, ward and parcel layers. actual: %v", layers)
+	}
+}
+
+func TestLoadAreaLayersRejectsUnknownFields(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "areas.json")
+	if err := os.WriteFile(path, []byte(`{"layers": [{"field": "county", "file": "counties.geojson", "property": "NAME"}]}`), 0666); err != nil {
+		t.Fatal(err)
+	}
+	var fileErr *FileError
+	if _, err := LoadAreaLayers(path); !errors.As(err, &fileErr) {
+		t.Errorf("Expected a FileError for an unknown field. actual: %v", err)
+	}
+}
+
+func TestEnrichAddressesTagsAreas(t *testing.T) {
+	layers, err := LoadAreaLayers("testdata/areas/areas.json")
+	if err != nil {
+		t.Fatalf("Expected no errors loading area layers. Found %v", err)
+	}
+	addresses := make(chan Address, 3)
+	// The Loop, Oak Park, which is also inside the first municipality in the test data, and Evanston.
+	addresses <- Address{Number: 1200, City: "CHICAGO", Latitude: 41.8817, Longitude: -87.6579}
+	addresses <- Address{Number: 100, City: "OAK PARK", Latitude: 41.8850, Longitude: -87.7845}
+	addresses <- Address{Number: 1, City: "EVANSTON", Latitude: 42.0451, Longitude: -87.6877}
+	close(addresses)
+
+	enriched := make(chan Address)
+	go EnrichAddresses(layers, addresses, enriched)
+
+	var actual []Address
+	for address := range enriched {
+		actual = append(actual, address)
+	}
+	if len(actual) != 3 {
+		t.Fatalf("Expected every address to be passed on. actual: %v", actual)
+	}
+	if actual[0].Municipality != "CHICAGO" || actual[0].Ward != "27" || actual[0].CensusTract != "" || actual[0].Pin != "17084370120000" {
+		t.Errorf("Unexpected areas for the Loop. actual: %v", actual[0])
+	}
+	if actual[1].Municipality != "CHICAGO" || actual[1].Ward != "" {
+		t.Errorf("Expected the first containing area to be used. actual: %v", actual[1])
+	}
+	if actual[2].Municipality != "" || actual[2].Ward != "" {
+		t.Errorf("Expected no areas outside every layer. actual: %v", actual[2])
+	}
+}
+
+func TestAreaLayersKeepSourcePin(t *testing.T) {
+	layers, err := LoadAreaLayers("testdata/areas/areas.json")
+	if err != nil {
+		t.Fatalf("Expected no errors loading area layers. Found %v", err)
+	}
+	address := layers.Tag(Address{Number: 1200, Pin: "17084370121001", Latitude: 41.8817, Longitude: -87.6579})
+	if address.Pin != "17084370121001" {
+		t.Errorf("Expected the PIN from the source to be kept. actual: %v", address)
+	}
+}
+
+func TestAreaLayersTagAgainstManyAreas(t *testing.T) {
+	// A 300 by 300 grid of parcels 0.0005 degrees wide.
+	areas := make([]geo.Area, 0, 300*300)
+	for row := 0; row < 300; row++ {
+		for col := 0; col < 300; col++ {
+			lat, lon := 41.8+float64(row)*0.0005, -87.7+float64(col)*0.0005
+			ring := [][2]float64{{lon, lat}, {lon + 0.0005, lat}, {lon + 0.0005, lat + 0.0005}, {lon, lat + 0.0005}, {lon, lat}}
+			areas = append(areas, geo.NewArea(map[string]interface{}{"PIN14": fmt.Sprintf("%014d", row*300+col)}, geo.Polygon{ring}))
+		}
+	}
+	layers := AreaLayers{{Field: "pin", Property: "PIN14", Transforms: []string{"pin"}, Areas: geo.NewAreaIndex(areas)}}
+
+	for row := 0; row < 300; row += 7 {
+		for col := 0; col < 300; col += 11 {
+			address := layers.Tag(Address{Latitude: 41.8 + (float64(row)+0.5)*0.0005, Longitude: -87.7 + (float64(col)+0.5)*0.0005})
+			if expected := fmt.Sprintf("%014d", row*300+col); address.Pin != expected {
+				t.Fatalf("Unexpected PIN for parcel %d,%d. actual: %s expected: %s", row, col, address.Pin, expected)
+			}
+		}
+	}
+	if address := layers.Tag(Address{Latitude: 41.7, Longitude: -87.65}); address.Pin != "" {
+		t.Errorf("Expected no PIN outside every parcel. actual: %s", address.Pin)
+	}
+}
@@ -0,0 +1,69 @@
+package data
+
+import (
+	"cook-county-geocoder/shared/geo"
+	"fmt"
+	"sync/atomic"
+)
+
+// Boundary is the area every address point must fall inside, such as the county outline. A point outside the boundary
+// whose latitude and longitude are inside it once swapped was written the wrong way round. With FixSwapped the
+// coordinates are swapped back and the row is kept, otherwise it is rejected as swapped_coordinates.
+type Boundary struct {
+	Areas      []geo.Area
+	FixSwapped bool
+	// swapped counts the rows corrected by FixSwapped.
+	swapped int64
+}
+
+// LoadBoundary reads the boundary polygons from a GeoJSON file. A FileError is returned if the file cannot be read or
+// has no polygons.
+func LoadBoundary(path string, fixSwapped bool) (*Boundary, error) {
+	areas, err := geo.LoadAreas(path)
+	if err != nil {
+		return nil, &FileError{Path: path, Err: err}
+	}
+	if len(areas) == 0 {
+		return nil, &FileError{Path: path, Err: fmt.Errorf("no boundary polygons")}
+	}
+	return &Boundary{Areas: areas, FixSwapped: fixSwapped}, nil
+}
+
+// Contains reports whether the point is inside any of the boundary areas.
+func (b *Boundary) Contains(lat float64, lon float64) bool {
+	for _, area := range b.Areas {
+		if area.Contains(lat, lon) {
+			return true
+		}
+	}
+	return false
+}
+
+// Swapped returns the number of rows whose swapped coordinates have been corrected.
+func (b *Boundary) Swapped() int64 {
+	return atomic.LoadInt64(&b.swapped)
+}
+
+// check rejects addresses outside the boundary and corrects swapped coordinates. A nil Boundary accepts every address.
+func (b *Boundary) check(address Address) (Address, error) {
+	if b == nil || b.Contains(address.Latitude, address.Longitude) {
+		return address, nil
+	}
+	if !b.Contains(address.Longitude, address.Latitude) {
+		return Address{}, &RowError{
+			Category: CategoryOutsideBoundary,
+			Fields:   []string{"longitude", "latitude"},
+			Message:  fmt.Sprintf("location is outside of the boundary. longitude- %f latitude- %f full struct- %v", address.Longitude, address.Latitude, address),
+		}
+	}
+	if !b.FixSwapped {
+		return Address{}, &RowError{
+			Category: CategorySwappedCoordinates,
+			Fields:   []string{"longitude", "latitude"},
+			Message:  fmt.Sprintf("longitude and latitude are swapped. longitude- %f latitude- %f full struct- %v", address.Longitude, address.Latitude, address),
+		}
+	}
+	atomic.AddInt64(&b.swapped, 1)
+	address.Longitude, address.Latitude = address.Latitude, address.Longitude
+	return address, nil
+}
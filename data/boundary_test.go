@@ -0,0 +1,94 @@
+package data
+
+import (
+	"errors"
+	"testing"
+)
+
+func cookCountyBoundary(t *testing.T, fixSwapped bool) *Boundary {
+	boundary, err := LoadBoundary("boundaries/cook_county.geojson", fixSwapped)
+	if err != nil {
+		t.Fatalf("Could not load the Cook County boundary %s", err)
+	}
+	return boundary
+}
+
+func TestCookCountyBoundary(t *testing.T) {
+	boundary := cookCountyBoundary(t, true)
+	cases := map[string]struct {
+		lat, lon float64
+		expected bool
+	}{
+		"Loop":       {41.8817, -87.6298, true},
+		"Evanston":   {42.0451, -87.6877, true},
+		"Barrington": {42.1539, -88.1362, true},
+		"Lemont":     {41.6736, -88.0017, true},
+		"Steger":     {41.4700, -87.6364, true},
+		"Naperville": {41.7508, -88.1535, false},
+		"Waukegan":   {42.3636, -87.8448, false},
+		"Gary":       {41.5934, -87.3464, false},
+		"Joliet":     {41.5250, -88.0817, false},
+		"Lake":       {41.9000, -87.4000, false},
+	}
+	for name, c := range cases {
+		if actual := boundary.Contains(c.lat, c.lon); actual != c.expected {
+			t.Errorf("Unexpected containment of %s. actual: %v", name, actual)
+		}
+	}
+}
+
+func TestBoundaryCheck(t *testing.T) {
+	boundary := cookCountyBoundary(t, true)
+	inside := Address{Street: "MADISON", Latitude: 41.8817, Longitude: -87.6579}
+	if actual, err := boundary.check(inside); err != nil || actual != inside {
+		t.Errorf("Expected an address inside the boundary to be kept. actual: %v err: %v", actual, err)
+	}
+
+	swapped := Address{Street: "MADISON", Latitude: -87.6579, Longitude: 41.8817}
+	actual, err := boundary.check(swapped)
+	if err != nil || actual != inside || boundary.Swapped() != 1 {
+		t.Errorf("Expected swapped coordinates to be corrected. actual: %v err: %v swapped: %d", actual, err, boundary.Swapped())
+	}
+
+	var rowErr *RowError
+	_, err = cookCountyBoundary(t, false).check(swapped)
+	if !errors.As(err, &rowErr) || rowErr.Category != CategorySwappedCoordinates {
+		t.Errorf("Expected swapped coordinates to be rejected without FixSwapped. actual: %v", err)
+	}
+
+	// Longitude -95 is within range but far outside the county.
+	_, err = boundary.check(Address{Street: "MADISON", Latitude: 41.8817, Longitude: -95})
+	if !errors.As(err, &rowErr) || rowErr.Category != CategoryOutsideBoundary {
+		t.Errorf("Expected an address outside the boundary to be rejected. actual: %v", err)
+	}
+
+	var none *Boundary
+	if actual, err := none.check(swapped); err != nil || actual != swapped {
+		t.Errorf("Expected a nil boundary to accept every address. actual: %v err: %v", actual, err)
+	}
+}
+
+func TestCsvReaderChecksBoundary(t *testing.T) {
+	fileName := writeCookCountyCsv(t,
+		cookCountyRow("1200", "MADISON", "CHICAGO", "-87.6579", "41.8817"),
+		cookCountyRow("1201", "MADISON", "CHICAGO", "41.8817", "-87.6581"),
+		cookCountyRow("1202", "MADISON", "CHICAGO", "-95.0", "41.8817"),
+	)
+	normalized := make(chan Address, 10)
+	rejected := make(chan RejectedRow, 10)
+	if err := CsvReader(fileName, cookCountySchema(t), cookCountyBoundary(t, true), nil, normalized, rejected); err != nil {
+		t.Fatalf("Expected no errors. Found %v", err)
+	}
+
+	var addresses []Address
+	for address := range normalized {
+		addresses = append(addresses, address)
+	}
+	if len(addresses) != 2 || addresses[1].Latitude != 41.8817 || addresses[1].Longitude != -87.6581 {
+		t.Errorf("Expected the swapped row to be corrected. actual: %v", addresses)
+	}
+	row := <-rejected
+	if row.Line != 4 || row.Category != CategoryOutsideBoundary {
+		t.Errorf("Expected the row outside the county to be rejected. actual: %v", row)
+	}
+}
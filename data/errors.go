@@ -0,0 +1,112 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Errors returned by the data package. Callers can inspect them with errors.Is and errors.As to decide how to recover.
+
+var (
+	// ErrIndexExists is wrapped by IndexError when creating an index that already exists.
+	ErrIndexExists = errors.New("index already exists")
+	// ErrIndexNotFound is wrapped by IndexError when the index does not exist.
+	ErrIndexNotFound = errors.New("index not found")
+	// ErrAliasIsIndex is wrapped by IndexError when an index, rather than an alias, already has the alias name.
+	ErrAliasIsIndex = errors.New("alias name is used by an index")
+	// ErrRemoteSource is wrapped by FileError when a source that must be a local CSV file is a URL.
+	ErrRemoteSource = errors.New("source is a URL, not a CSV file")
+)
+
+// FileError is returned when an input file cannot be opened or read. Line is 0 when the file could not be opened.
+type FileError struct {
+	Path string
+	Line int
+	Err  error
+}
+
+func (e *FileError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("error reading %s at line %d: %s", e.Path, e.Line, e.Err)
+	}
+	return fmt.Sprintf("error reading %s: %s", e.Path, e.Err)
+}
+
+func (e *FileError) Unwrap() error {
+	return e.Err
+}
+
+// HeaderError is returned when the header row of a source file is missing required columns of its schema.
+type HeaderError struct {
+	Schema  string
+	Missing []string
+}
+
+func (e *HeaderError) Error() string {
+	return fmt.Sprintf("error mapping header columns for schema %s. missing: %s", e.Schema, strings.Join(e.Missing, ", "))
+}
+
+// RequestError is returned when a page of a remote source cannot be fetched or decoded. StatusCode is 0 when no response
+// was received.
+type RequestError struct {
+	Url        string
+	StatusCode int
+	Err        error
+}
+
+func (e *RequestError) Error() string {
+	if e.StatusCode > 0 {
+		return fmt.Sprintf("error requesting %s- status %d: %s", e.Url, e.StatusCode, e.Err)
+	}
+	return fmt.Sprintf("error requesting %s: %s", e.Url, e.Err)
+}
+
+func (e *RequestError) Unwrap() error {
+	return e.Err
+}
+
+// IndexError is returned when an Elasticsearch index operation fails. StatusCode is 0 when no response was received.
+type IndexError struct {
+	Op         string
+	Index      string
+	StatusCode int
+	Err        error
+}
+
+func (e *IndexError) Error() string {
+	if e.StatusCode > 0 {
+		return fmt.Sprintf("cannot %s index %s- status %d: %s", e.Op, e.Index, e.StatusCode, e.Err)
+	}
+	return fmt.Sprintf("cannot %s index %s: %s", e.Op, e.Index, e.Err)
+}
+
+func (e *IndexError) Unwrap() error {
+	return e.Err
+}
+
+// BulkIndexError is returned when some documents in a bulk load were not indexed.
+type BulkIndexError struct {
+	Index   string
+	Indexed uint64
+	Failed  uint64
+}
+
+func (e *BulkIndexError) Error() string {
+	return fmt.Sprintf("bulk indexing into %s failed for %d documents, %d indexed", e.Index, e.Failed, e.Indexed)
+}
+
+// CountError is returned when the documents in a newly loaded index do not match the number indexed, or nothing was
+// indexed at all. The alias is not moved to the index.
+type CountError struct {
+	Index    string
+	Expected uint64
+	Actual   uint64
+}
+
+func (e *CountError) Error() string {
+	if e.Expected == 0 {
+		return fmt.Sprintf("no documents were indexed into %s", e.Index)
+	}
+	return fmt.Sprintf("index %s has %d documents, expected %d", e.Index, e.Actual, e.Expected)
+}
@@ -0,0 +1,112 @@
+package data
+
+import (
+	"bytes"
+	"cook-county-geocoder/shared/grid"
+	"cook-county-geocoder/shared/mapping"
+	"encoding/json"
+	"github.com/elastic/go-elasticsearch/v7"
+	"github.com/elastic/go-elasticsearch/v7/esapi"
+	"log"
+	"strings"
+	"time"
+)
+
+const (
+	// GridCity is the city whose address points follow the Chicago grid. Suburbs number their own addresses.
+	GridCity = "CHICAGO"
+
+	gridScrollSize    = 5000
+	gridScrollTimeout = time.Minute
+)
+
+// BuildGridModel scrolls through the indexed Chicago address points with a directional prefix and fits the grid model
+// to them.
+func BuildGridModel(es *elasticsearch.Client, indexName string) (grid.Model, error) {
+	query := map[string]interface{}{
+		"query": map[string]interface{}{
+			"bool": map[string]interface{}{
+				"filter": []interface{}{
+					map[string]interface{}{"match": map[string]interface{}{"city": GridCity}},
+					map[string]interface{}{"terms": map[string]interface{}{"street_prefix": []string{"N", "S", "E", "W"}}},
+				},
+			},
+		},
+		"_source": []string{"number", "street_prefix", "city", "lat_long"},
+	}
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(query); err != nil {
+		return grid.Model{}, &IndexError{Op: "scroll", Index: indexName, Err: err}
+	}
+
+	res, err := es.Search(
+		es.Search.WithIndex(indexName),
+		es.Search.WithBody(&buf),
+		es.Search.WithSize(gridScrollSize),
+		es.Search.WithScroll(gridScrollTimeout),
+	)
+
+	var builder grid.Builder
+	points := 0
+	scrollId := ""
+	for {
+		if err != nil {
+			return grid.Model{}, &IndexError{Op: "scroll", Index: indexName, Err: err}
+		}
+		page, pageErr := readScrollPage(res, indexName)
+		if pageErr != nil {
+			return grid.Model{}, pageErr
+		}
+		scrollId = page.ScrollId
+		if len(page.Hits.Hits) == 0 {
+			break
+		}
+		for _, hit := range page.Hits.Hits {
+			// The city filter also matches CHICAGO HEIGHTS and CHICAGO RIDGE.
+			if strings.ToUpper(hit.Source.City) != GridCity {
+				continue
+			}
+			builder.Add(hit.Source.Number, hit.Source.StreetPrefix, hit.Source.LatLong.Latitude, hit.Source.LatLong.Longitude)
+			points++
+		}
+		res, err = es.Scroll(es.Scroll.WithScrollID(scrollId), es.Scroll.WithScroll(gridScrollTimeout))
+	}
+	clearScroll(es, scrollId)
+
+	log.Printf("Fitting grid model to %d address points\n", points)
+	return builder.Model()
+}
+
+type scrollPage struct {
+	ScrollId string `json:"_scroll_id"`
+	Hits     struct {
+		Hits []struct {
+			Source mapping.EsAddress `json:"_source"`
+		} `json:"hits"`
+	} `json:"hits"`
+}
+
+func readScrollPage(res *esapi.Response, indexName string) (scrollPage, error) {
+	defer res.Body.Close()
+	if res.IsError() {
+		return scrollPage{}, &IndexError{Op: "scroll", Index: indexName, StatusCode: res.StatusCode, Err: responseError(res)}
+	}
+	var page scrollPage
+	if err := json.NewDecoder(res.Body).Decode(&page); err != nil {
+		return scrollPage{}, &IndexError{Op: "scroll", Index: indexName, Err: err}
+	}
+	return page, nil
+}
+
+// clearScroll releases the scroll context. Scrolls expire on their own, so failures are only logged.
+func clearScroll(es *elasticsearch.Client, scrollId string) {
+	if scrollId == "" {
+		return
+	}
+	res, err := es.ClearScroll(es.ClearScroll.WithScrollID(scrollId))
+	if err != nil {
+		log.Printf("Could not clear scroll: %s\n", err)
+		return
+	}
+	_ = res.Body.Close()
+}
@@ -0,0 +1,45 @@
+package data
+
+import (
+	"cook-county-geocoder/shared/mapping"
+	"math"
+	"testing"
+)
+
+func TestBuildGridModel(t *testing.T) {
+	beforeEach()
+
+	addresses := make([]Address, 0)
+	for number := 0; number < 4000; number += 20 {
+		addresses = append(addresses,
+			Address{Number: number, StreetPrefix: "N", Street: "WELLS", City: "CHICAGO", Zip5: "60610", Latitude: 41.882 + 0.0000181*float64(number), Longitude: -87.634},
+			Address{Number: number, StreetPrefix: "W", Street: "MADISON", City: "CHICAGO", Zip5: "60606", Latitude: 41.882, Longitude: -87.628 - 0.0000243*float64(number)},
+			// Chicago Heights has its own grid.
+			Address{Number: number, StreetPrefix: "W", Street: "MAIN", City: "CHICAGO HEIGHTS", Zip5: "60411", Latitude: 41.5, Longitude: -87.6},
+		)
+	}
+	esAddresses := make(chan mapping.EsAddress)
+	go func() {
+		for _, address := range addresses {
+			esAddresses <- ToEsAddress(address)
+		}
+		close(esAddresses)
+	}()
+	if _, err := BulkIndexEs(client, addressIndex, esAddresses); err != nil {
+		t.Fatalf("Expected no errors bulk indexing. Found %v", err)
+	}
+	if err := RefreshIndex(client, addressIndex); err != nil {
+		t.Fatal(err)
+	}
+
+	model, err := BuildGridModel(client, addressIndex)
+	if err != nil {
+		t.Fatalf("Expected no errors building the grid model. Found %v", err)
+	}
+	if model.NorthSouth.Samples != 200 || model.EastWest.Samples != 200 {
+		t.Errorf("Expected only Chicago points. actual: %v", model)
+	}
+	if math.Abs(model.EastWest.Slope-0.0000243) > 1e-9 || math.Abs(model.EastWest.Intercept+87.628) > 1e-6 {
+		t.Errorf("Unexpected east west axis %v", model.EastWest)
+	}
+}
@@ -3,19 +3,23 @@ package data
 import (
 	"bytes"
 	"context"
+	"cook-county-geocoder/shared/mapping"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"github.com/cenkalti/backoff/v4"
 	"github.com/elastic/go-elasticsearch/v7"
+	"github.com/elastic/go-elasticsearch/v7/esapi"
 	"github.com/elastic/go-elasticsearch/v7/esutil"
 	"log"
 	"os"
 	"strings"
+	"sync/atomic"
 	"time"
-	"cook-county-geocoder/shared/mapping"
 )
 
 // Receive normalized structs and index ES.
-func BuildEsClient(hosts []string) *elasticsearch.Client {
+func BuildEsClient(hosts []string) (*elasticsearch.Client, error) {
 	retryBackoff := backoff.NewExponentialBackOff()
 
 	es, err := elasticsearch.NewClient(elasticsearch.Config{
@@ -30,25 +34,35 @@ func BuildEsClient(hosts []string) *elasticsearch.Client {
 		MaxRetries: 5,
 	})
 	if err != nil {
-		log.Fatalf("Error creating the client: %s", err)
+		return nil, fmt.Errorf("error creating the client: %w", err)
 	}
-	return es
+	return es, nil
 }
 
-func DoesIndexExist(es *elasticsearch.Client, indexName string) bool {
+func DoesIndexExist(es *elasticsearch.Client, indexName string) (bool, error) {
 	res, err := es.Indices.Exists(
 		[]string{indexName},
 	)
 	if err != nil {
-		log.Printf("Error calling index exists API: %s\n", err)
+		return false, &IndexError{Op: "check", Index: indexName, Err: err}
+	}
+	_ = res.Body.Close()
+	switch res.StatusCode {
+	case 200:
+		return true, nil
+	case 404:
+		return false, nil
+	default:
+		return false, &IndexError{Op: "check", Index: indexName, StatusCode: res.StatusCode, Err: errors.New(res.Status())}
 	}
-	return res.StatusCode == 200
 }
 
-func CreateIndex(es *elasticsearch.Client, filePath string, indexName string) {
+// CreateIndex creates the index from a settings and mappings file. Creating an index that already exists returns an
+// IndexError wrapping ErrIndexExists.
+func CreateIndex(es *elasticsearch.Client, filePath string, indexName string) error {
 	file, err := os.ReadFile(filePath)
 	if err != nil {
-		log.Fatalf("Error reading index file: %s", err)
+		return &FileError{Path: filePath, Err: err}
 	}
 	stripped := strings.Join(strings.Fields(string(file)), "")
 	body := strings.NewReader(stripped)
@@ -58,57 +72,115 @@ func CreateIndex(es *elasticsearch.Client, filePath string, indexName string) {
 		es.Indices.Create.WithWaitForActiveShards("1"),
 	)
 	if err != nil {
-		log.Fatalf("Cannot create index- request creation error: %s", err)
+		return &IndexError{Op: "create", Index: indexName, Err: err}
 	}
+	defer res.Body.Close()
 	if res.IsError() {
-		log.Fatalf("Cannot create index- response error: %s", res)
+		return &IndexError{Op: "create", Index: indexName, StatusCode: res.StatusCode, Err: responseError(res)}
 	}
 	log.Printf("Created index %s\n", indexName)
-	_ = res.Body.Close()
+	return nil
 }
 
-func DeleteIndex(es *elasticsearch.Client, indexName string) {
+// DeleteIndex deletes the index. Deleting an index that does not exist returns an IndexError wrapping ErrIndexNotFound.
+func DeleteIndex(es *elasticsearch.Client, indexName string) error {
 	res, err := es.Indices.Delete([]string{indexName})
-
 	if err != nil {
-		log.Fatalf("Cannot delete index: %s", err)
+		return &IndexError{Op: "delete", Index: indexName, Err: err}
 	}
+	defer res.Body.Close()
 	if res.IsError() {
-		log.Fatalf("Cannot delete index: %s", res)
+		return &IndexError{Op: "delete", Index: indexName, StatusCode: res.StatusCode, Err: responseError(res)}
 	}
 	log.Printf("Deleted index %s\n", indexName)
-	_ = res.Body.Close()
+	return nil
+}
+
+// responseError reads the Elasticsearch error body, mapping known error types to the package sentinel errors.
+func responseError(res *esapi.Response) error {
+	var body struct {
+		Error struct {
+			Type   string `json:"type"`
+			Reason string `json:"reason"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil || body.Error.Type == "" {
+		if res.StatusCode == 404 {
+			return ErrIndexNotFound
+		}
+		return errors.New(res.Status())
+	}
+
+	switch body.Error.Type {
+	case "resource_already_exists_exception":
+		return fmt.Errorf("%w: %s", ErrIndexExists, body.Error.Reason)
+	case "index_not_found_exception":
+		return fmt.Errorf("%w: %s", ErrIndexNotFound, body.Error.Reason)
+	default:
+		return fmt.Errorf("%s: %s", body.Error.Type, body.Error.Reason)
+	}
 }
 
 const (
 	WORKERS = 5
 )
 
-func BulkIndexEs(es *elasticsearch.Client, indexName string, esAddresses []mapping.EsAddress) esutil.BulkIndexerStats {
+// BulkStats are the bulk indexer stats with the successful index operations split by result. Documents are indexed
+// under deterministic IDs, so a source row that repeats an earlier one overwrites its document: NumIndexed counts both
+// operations, while only NumCreated added a document to the index.
+type BulkStats struct {
+	esutil.BulkIndexerStats
+	NumCreated uint64
+	NumUpdated uint64
+}
+
+// BulkIndexEs indexes documents as they arrive until the channel is closed. The bulk indexer blocks when its workers
+// are busy, which pushes back on the producers feeding the channel. If any document fails, the stats are returned with
+// a BulkIndexError holding the counts. The channel is always drained, so producers are never left blocked.
+func BulkIndexEs(es *elasticsearch.Client, indexName string, esAddresses <-chan mapping.EsAddress) (BulkStats, error) {
 	bulkIndexer, err := esutil.NewBulkIndexer(esutil.BulkIndexerConfig{
 		Index:      indexName,
 		Client:     es,
 		NumWorkers: WORKERS,
 	})
 	if err != nil {
-		log.Fatalf("Error creating the indexer: %s", err)
+		drain(esAddresses)
+		return BulkStats{}, &IndexError{Op: "bulk index", Index: indexName, Err: err}
+	}
+
+	var created, updated uint64
+	stats := func() BulkStats {
+		return BulkStats{
+			BulkIndexerStats: bulkIndexer.Stats(),
+			NumCreated:       atomic.LoadUint64(&created),
+			NumUpdated:       atomic.LoadUint64(&updated),
+		}
 	}
 
 	start := time.Now().UTC()
 
-	for _, esAddress := range esAddresses {
+	for esAddress := range esAddresses {
 		// Encode article to JSON
 		data, err := json.Marshal(esAddress)
 		if err != nil {
-			log.Fatalf("Cannot encode address document %v: %s", esAddress, err)
+			drain(esAddresses)
+			_ = bulkIndexer.Close(context.Background())
+			return stats(), fmt.Errorf("cannot encode address document %v: %w", esAddress, err)
 		}
 
 		err = bulkIndexer.Add(
 			context.Background(),
 			esutil.BulkIndexerItem{
-				Action:    "index",
-				Body:      bytes.NewReader(data),
-				OnSuccess: func(ctx context.Context, item esutil.BulkIndexerItem, res esutil.BulkIndexerResponseItem) {},
+				Action:     "index",
+				DocumentID: esAddress.Id,
+				Body:       bytes.NewReader(data),
+				OnSuccess: func(ctx context.Context, item esutil.BulkIndexerItem, res esutil.BulkIndexerResponseItem) {
+					if res.Result == "updated" {
+						atomic.AddUint64(&updated, 1)
+					} else {
+						atomic.AddUint64(&created, 1)
+					}
+				},
 				OnFailure: func(ctx context.Context, item esutil.BulkIndexerItem, res esutil.BulkIndexerResponseItem, err error) {
 					if err != nil {
 						log.Printf("ERROR: %s", err)
@@ -119,32 +191,41 @@ func BulkIndexEs(es *elasticsearch.Client, indexName string, esAddresses []mappi
 			},
 		)
 		if err != nil {
-			log.Fatalf("Unexpected error: %s", err)
+			drain(esAddresses)
+			_ = bulkIndexer.Close(context.Background())
+			return stats(), &IndexError{Op: "bulk index", Index: indexName, Err: err}
 		}
 	}
 
 	if err := bulkIndexer.Close(context.Background()); err != nil {
-		log.Fatalf("Unexpected error: %s", err)
+		return stats(), &IndexError{Op: "bulk index", Index: indexName, Err: err}
 	}
 
-	biStats := bulkIndexer.Stats()
+	biStats := stats()
 	dur := time.Since(start)
 
 	if biStats.NumFailed > 0 {
-		log.Fatalf(
+		log.Printf(
 			"Indexed [%d] documents with [%d] errors in %s (%d docs/sec)",
 			int64(biStats.NumIndexed),
 			int64(biStats.NumFailed),
 			dur.Truncate(time.Millisecond),
 			int64(1000.0/float64(dur/time.Millisecond)*float64(biStats.NumFlushed)),
 		)
-	} else {
-		log.Printf(
-			"Sucessfuly indexed [%d] documents in %s (%d docs/sec)",
-			int64(biStats.NumIndexed),
-			dur.Truncate(time.Millisecond),
-			int64(1000.0/float64(dur/time.Millisecond)*float64(biStats.NumFlushed)),
-		)
+		return biStats, &BulkIndexError{Index: indexName, Indexed: biStats.NumIndexed, Failed: biStats.NumFailed}
+	}
+	log.Printf(
+		"Sucessfuly indexed [%d] documents, [%d] overwriting an earlier document, in %s (%d docs/sec)",
+		int64(biStats.NumIndexed),
+		int64(biStats.NumUpdated),
+		dur.Truncate(time.Millisecond),
+		int64(1000.0/float64(dur/time.Millisecond)*float64(biStats.NumFlushed)),
+	)
+	return biStats, nil
+}
+
+// drain discards the rest of the channel so the goroutines feeding it can finish.
+func drain(esAddresses <-chan mapping.EsAddress) {
+	for range esAddresses {
 	}
-	return biStats
 }
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"cook-county-geocoder/shared/mapping"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/elastic/go-elasticsearch/v7"
 	"github.com/orlangure/gnomock"
@@ -28,7 +29,7 @@ func TestMain(m *testing.M) {
 	endpoint, ciMode := os.LookupEnv("IT_ES_ENDPOINT")
 	if ciMode {
 		log.Println("Using ES service for CI.")
-		client = BuildEsClient([]string{fmt.Sprintf("http://%s", endpoint)})
+		client = mustBuildEsClient(fmt.Sprintf("http://%s", endpoint))
 	} else {
 		es := elastic.Preset(
 			elastic.WithVersion("7.9.0"),
@@ -41,7 +42,7 @@ func TestMain(m *testing.M) {
 
 		defer func() { _ = gnomock.Stop(container) }()
 
-		client = BuildEsClient([]string{fmt.Sprintf("http://%s", container.DefaultAddress())})
+		client = mustBuildEsClient(fmt.Sprintf("http://%s", container.DefaultAddress()))
 	}
 
 	exitVal := m.Run()
@@ -52,16 +53,22 @@ func TestMain(m *testing.M) {
 func TestBulkIndex(t *testing.T) {
 	beforeEach()
 
-	var addresses []mapping.EsAddress
-	for i := 100; i < 10100; i++ {
-		addresses = append(addresses, buildTestEsAddress(i))
-	}
+	addresses := make(chan mapping.EsAddress)
+	go func() {
+		for i := 100; i < 10100; i++ {
+			addresses <- buildTestEsAddress(i)
+		}
+		close(addresses)
+	}()
 
-	stats := BulkIndexEs(client, addressIndex, addresses)
+	stats, err := BulkIndexEs(client, addressIndex, addresses)
+	if err != nil {
+		t.Errorf("Expected no errors bulk indexing. Found %v", err)
+	}
 
 	// Check the stats for successful indexed count
 	indexedCount := stats.NumIndexed
-	expectedIndexedCount := uint64(len(addresses))
+	expectedIndexedCount := uint64(10000)
 
 	if indexedCount != expectedIndexedCount {
 		t.Errorf("Indexed document count (%d) does not equal expected indexed document count (%d)", indexedCount, expectedIndexedCount)
@@ -74,12 +81,44 @@ func TestBulkIndex(t *testing.T) {
 	}
 }
 
+func TestCreateIndexWhenIndexExists(t *testing.T) {
+	beforeEach()
+
+	err := CreateIndex(client, "../shared/mapping/es_index_v_0_1.json", addressIndex)
+	if !errors.Is(err, ErrIndexExists) {
+		t.Errorf("Expected ErrIndexExists when creating an existing index. actual: %v", err)
+	}
+}
+
+func TestDeleteIndexWhenIndexDoesNotExist(t *testing.T) {
+	err := DeleteIndex(client, "address_test_missing")
+	if !errors.Is(err, ErrIndexNotFound) {
+		t.Errorf("Expected ErrIndexNotFound when deleting a missing index. actual: %v", err)
+	}
+}
+
 // Helper test functions
 func beforeEach() {
-	if DoesIndexExist(client, addressIndex) {
-		DeleteIndex(client, addressIndex)
+	exists, err := DoesIndexExist(client, addressIndex)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if exists {
+		if err := DeleteIndex(client, addressIndex); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if err := CreateIndex(client, "../shared/mapping/es_index_v_0_1.json", addressIndex); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func mustBuildEsClient(host string) *elasticsearch.Client {
+	es, err := BuildEsClient([]string{host})
+	if err != nil {
+		log.Fatal(err)
 	}
-	CreateIndex(client, "../shared/mapping/es_index_v_0_1.json", addressIndex)
+	return es
 }
 
 func buildTestEsAddress(number int) mapping.EsAddress {
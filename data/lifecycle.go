@@ -0,0 +1,243 @@
+package data
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/elastic/go-elasticsearch/v7"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Index lifecycle. Each load goes into a new versioned index, and the alias the API reads from is only moved once the
+// new index is fully loaded and validated, so searches never see a half built index.
+
+// ReindexConfig controls a versioned reindex. Alias is the name the API searches, MappingFile the settings and mappings
+// used to create the new index and Version the mapping version recorded in the index name. When DeleteOld is set, every
+// other version of the alias is deleted after the swap.
+type ReindexConfig struct {
+	Alias       string
+	MappingFile string
+	Version     int
+	DeleteOld   bool
+}
+
+// LoadFunc bulk loads documents into the named index and returns the bulk indexer stats.
+type LoadFunc func(indexName string) (BulkStats, error)
+
+// VersionedIndexName returns the name of a new index for the alias, in the form <alias>_v<version>_<timestamp>. The
+// timestamp is UTC with millisecond precision, so names sort by creation time.
+func VersionedIndexName(alias string, version int, created time.Time) string {
+	created = created.UTC()
+	return fmt.Sprintf("%s_v%d_%s%03d", alias, version, created.Format("20060102150405"), created.Nanosecond()/int(time.Millisecond))
+}
+
+// Reindex creates a versioned index from the mapping, loads it, checks that Elasticsearch holds as many documents as
+// the load created, then atomically points the alias at it. The new index is deleted if any step before the swap fails,
+// and the alias is left on the previous version. The name of the new index is returned.
+func Reindex(es *elasticsearch.Client, config ReindexConfig, load LoadFunc) (string, error) {
+	previous, err := AliasIndices(es, config.Alias)
+	if err != nil {
+		return "", err
+	}
+	if len(previous) == 0 {
+		// An index, rather than an alias, with the alias name cannot be swapped.
+		exists, err := DoesIndexExist(es, config.Alias)
+		if err != nil {
+			return "", err
+		}
+		if exists {
+			return "", &IndexError{Op: "alias", Index: config.Alias, Err: ErrAliasIsIndex}
+		}
+	}
+
+	indexName := VersionedIndexName(config.Alias, config.Version, time.Now())
+	if err := CreateIndex(es, config.MappingFile, indexName); err != nil {
+		return "", err
+	}
+
+	if err := loadAndValidate(es, indexName, load); err != nil {
+		if deleteErr := DeleteIndex(es, indexName); deleteErr != nil {
+			log.Printf("Could not delete failed index %s: %s\n", indexName, deleteErr)
+		}
+		return "", err
+	}
+
+	if err := SwapAlias(es, config.Alias, indexName, previous); err != nil {
+		if deleteErr := DeleteIndex(es, indexName); deleteErr != nil {
+			log.Printf("Could not delete failed index %s: %s\n", indexName, deleteErr)
+		}
+		return "", err
+	}
+	log.Printf("Alias %s now points to %s\n", config.Alias, indexName)
+
+	if config.DeleteOld {
+		if err := DeleteOldVersions(es, config.Alias, indexName); err != nil {
+			return indexName, err
+		}
+	}
+	return indexName, nil
+}
+
+// loadAndValidate runs the load, refreshes the index so every document is searchable, and compares the document count
+// with the number of documents the load created. Rows that overwrote an earlier document with the same ID are indexed
+// but do not add to the count.
+func loadAndValidate(es *elasticsearch.Client, indexName string, load LoadFunc) error {
+	stats, err := load(indexName)
+	if err != nil {
+		return err
+	}
+	if err := RefreshIndex(es, indexName); err != nil {
+		return err
+	}
+	count, err := CountDocuments(es, indexName)
+	if err != nil {
+		return err
+	}
+	if stats.NumCreated == 0 || count != stats.NumCreated {
+		return &CountError{Index: indexName, Expected: stats.NumCreated, Actual: count}
+	}
+	return nil
+}
+
+// RefreshIndex makes every indexed document visible to search and count requests.
+func RefreshIndex(es *elasticsearch.Client, indexName string) error {
+	res, err := es.Indices.Refresh(es.Indices.Refresh.WithIndex(indexName))
+	if err != nil {
+		return &IndexError{Op: "refresh", Index: indexName, Err: err}
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		return &IndexError{Op: "refresh", Index: indexName, StatusCode: res.StatusCode, Err: responseError(res)}
+	}
+	return nil
+}
+
+// CountDocuments returns the number of searchable documents in the index or alias.
+func CountDocuments(es *elasticsearch.Client, indexName string) (uint64, error) {
+	res, err := es.Count(es.Count.WithIndex(indexName))
+	if err != nil {
+		return 0, &IndexError{Op: "count", Index: indexName, Err: err}
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		return 0, &IndexError{Op: "count", Index: indexName, StatusCode: res.StatusCode, Err: responseError(res)}
+	}
+	var body struct {
+		Count uint64 `json:"count"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		return 0, &IndexError{Op: "count", Index: indexName, Err: err}
+	}
+	return body.Count, nil
+}
+
+// AliasIndices returns the sorted names of the indices the alias points to. A missing alias has no indices.
+func AliasIndices(es *elasticsearch.Client, alias string) ([]string, error) {
+	res, err := es.Indices.GetAlias(es.Indices.GetAlias.WithName(alias))
+	if err != nil {
+		return nil, &IndexError{Op: "get alias", Index: alias, Err: err}
+	}
+	defer res.Body.Close()
+	if res.StatusCode == 404 {
+		return nil, nil
+	}
+	if res.IsError() {
+		return nil, &IndexError{Op: "get alias", Index: alias, StatusCode: res.StatusCode, Err: responseError(res)}
+	}
+	var body map[string]json.RawMessage
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		return nil, &IndexError{Op: "get alias", Index: alias, Err: err}
+	}
+	return sortedKeys(body), nil
+}
+
+// SwapAlias points the alias at newIndex and removes it from the previous indices in a single atomic request.
+func SwapAlias(es *elasticsearch.Client, alias string, newIndex string, previous []string) error {
+	body, err := json.Marshal(aliasActions(alias, newIndex, previous))
+	if err != nil {
+		return &IndexError{Op: "alias", Index: newIndex, Err: err}
+	}
+	res, err := es.Indices.UpdateAliases(strings.NewReader(string(body)))
+	if err != nil {
+		return &IndexError{Op: "alias", Index: newIndex, Err: err}
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		return &IndexError{Op: "alias", Index: newIndex, StatusCode: res.StatusCode, Err: responseError(res)}
+	}
+	return nil
+}
+
+// aliasActions builds the _aliases request body that moves the alias from the previous indices to newIndex.
+func aliasActions(alias string, newIndex string, previous []string) map[string]interface{} {
+	actions := make([]map[string]interface{}, 0, len(previous)+1)
+	for _, index := range previous {
+		if index == newIndex {
+			continue
+		}
+		actions = append(actions, map[string]interface{}{"remove": map[string]string{"index": index, "alias": alias}})
+	}
+	actions = append(actions, map[string]interface{}{"add": map[string]string{"index": newIndex, "alias": alias}})
+	return map[string]interface{}{"actions": actions}
+}
+
+// DeleteOldVersions deletes every versioned index of the alias except current.
+func DeleteOldVersions(es *elasticsearch.Client, alias string, current string) error {
+	versions, err := versionedIndices(es, alias)
+	if err != nil {
+		return err
+	}
+	for _, index := range versions {
+		if index == current {
+			continue
+		}
+		if err := DeleteIndex(es, index); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// versionedIndices returns the sorted names of the indices created by Reindex for the alias.
+func versionedIndices(es *elasticsearch.Client, alias string) ([]string, error) {
+	pattern := alias + "_v*"
+	res, err := es.Indices.Get([]string{pattern}, es.Indices.Get.WithAllowNoIndices(true))
+	if err != nil {
+		return nil, &IndexError{Op: "list", Index: pattern, Err: err}
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		return nil, &IndexError{Op: "list", Index: pattern, StatusCode: res.StatusCode, Err: responseError(res)}
+	}
+	var body map[string]json.RawMessage
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		return nil, &IndexError{Op: "list", Index: pattern, Err: err}
+	}
+	versions := make([]string, 0, len(body))
+	for _, index := range sortedKeys(body) {
+		if isVersionOf(index, alias) {
+			versions = append(versions, index)
+		}
+	}
+	return versions, nil
+}
+
+func sortedKeys(m map[string]json.RawMessage) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// isVersionOf reports whether the index name was created by Reindex for the alias.
+func isVersionOf(indexName string, alias string) bool {
+	suffix := strings.TrimPrefix(indexName, alias)
+	return suffix != indexName && versionSuffixPattern.MatchString(suffix)
+}
+
+var versionSuffixPattern = regexp.MustCompile(`^_v\d+_\d{17}$`)
@@ -0,0 +1,157 @@
+package data
+
+import (
+	"cook-county-geocoder/shared/mapping"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+const (
+	lifecycleAlias = "address_lifecycle_test"
+	mappingFile    = "../shared/mapping/es_index_v_0_1.json"
+)
+
+func TestVersionedIndexName(t *testing.T) {
+	created := time.Date(2020, 10, 4, 15, 4, 5, 123456789, time.FixedZone("CDT", -5*60*60))
+	actual := VersionedIndexName("address", 2, created)
+	if actual != "address_v2_20201004200405123" {
+		t.Errorf("Unexpected versioned index name. actual: %s", actual)
+	}
+	if !isVersionOf(actual, "address") {
+		t.Errorf("Expected %s to be a version of address", actual)
+	}
+	if isVersionOf("address_visits", "address") || isVersionOf(actual, "addr") {
+		t.Errorf("Expected only indices created for the alias to be versions")
+	}
+}
+
+func TestAliasActions(t *testing.T) {
+	actual, _ := json.Marshal(aliasActions("address", "address_v1_2", []string{"address_v1_1", "address_v1_2"}))
+	expected := `{"actions":[{"remove":{"alias":"address","index":"address_v1_1"}},{"add":{"alias":"address","index":"address_v1_2"}}]}`
+	if string(actual) != expected {
+		t.Errorf("Unexpected alias actions. actual: %s expected: %s", actual, expected)
+	}
+}
+
+func TestReindexSwapsAliasAndDeletesOldVersions(t *testing.T) {
+	deleteLifecycleIndices(t)
+	config := ReindexConfig{Alias: lifecycleAlias, MappingFile: mappingFile, Version: 1, DeleteOld: true}
+
+	first, err := Reindex(client, config, loadTestAddresses(10))
+	if err != nil {
+		t.Fatalf("Expected no errors on the first reindex. Found %v", err)
+	}
+	second, err := Reindex(client, config, loadTestAddresses(20))
+	if err != nil {
+		t.Fatalf("Expected no errors on the second reindex. Found %v", err)
+	}
+
+	indices, err := AliasIndices(client, lifecycleAlias)
+	if err != nil || len(indices) != 1 || indices[0] != second {
+		t.Errorf("Expected the alias to point to %s only. actual: %v err: %v", second, indices, err)
+	}
+	if count, _ := CountDocuments(client, lifecycleAlias); count != 20 {
+		t.Errorf("Expected 20 documents behind the alias. actual: %d", count)
+	}
+	if exists, _ := DoesIndexExist(client, first); exists {
+		t.Errorf("Expected the old version %s to be deleted", first)
+	}
+}
+
+func TestReindexKeepsAliasWhenCountDoesNotMatch(t *testing.T) {
+	deleteLifecycleIndices(t)
+	config := ReindexConfig{Alias: lifecycleAlias, MappingFile: mappingFile, Version: 1}
+
+	first, err := Reindex(client, config, loadTestAddresses(10))
+	if err != nil {
+		t.Fatalf("Expected no errors on the first reindex. Found %v", err)
+	}
+
+	// Claim more documents were indexed than were sent.
+	_, err = Reindex(client, config, func(indexName string) (BulkStats, error) {
+		stats, err := loadTestAddresses(5)(indexName)
+		stats.NumCreated++
+		return stats, err
+	})
+	var countErr *CountError
+	if !errors.As(err, &countErr) {
+		t.Fatalf("Expected a CountError. actual: %v", err)
+	}
+
+	indices, _ := AliasIndices(client, lifecycleAlias)
+	if len(indices) != 1 || indices[0] != first {
+		t.Errorf("Expected the alias to stay on %s. actual: %v", first, indices)
+	}
+	if exists, _ := DoesIndexExist(client, countErr.Index); exists {
+		t.Errorf("Expected the failed index %s to be deleted", countErr.Index)
+	}
+}
+
+func TestReindexWithRepeatedDocumentIds(t *testing.T) {
+	deleteLifecycleIndices(t)
+	config := ReindexConfig{Alias: lifecycleAlias, MappingFile: mappingFile, Version: 1}
+
+	// Two source rows for the same address in different cities share an ID, so the second overwrites the first.
+	var stats BulkStats
+	indexName, err := Reindex(client, config, func(indexName string) (BulkStats, error) {
+		addresses := make(chan mapping.EsAddress)
+		go func() {
+			for _, city := range []string{"CHICAGO", "EVANSTON"} {
+				address := buildTestEsAddress(100)
+				address.City = city
+				address.Id = CalculateId(Address{Number: address.Number, Street: address.Street, City: city})
+				addresses <- address
+			}
+			close(addresses)
+		}()
+		var err error
+		stats, err = BulkIndexEs(client, indexName, addresses)
+		return stats, err
+	})
+	if err != nil {
+		t.Fatalf("Expected repeated IDs to pass validation. Found %v", err)
+	}
+	if stats.NumIndexed != 2 || stats.NumCreated != 1 || stats.NumUpdated != 1 {
+		t.Errorf("Expected 2 indexed, 1 created and 1 updated. actual: %+v", stats)
+	}
+	if count, _ := CountDocuments(client, lifecycleAlias); count != 1 {
+		t.Errorf("Expected 1 document behind the alias %s. actual: %d", indexName, count)
+	}
+}
+
+func TestReindexWhenAliasNameIsAnIndex(t *testing.T) {
+	deleteLifecycleIndices(t)
+	if err := CreateIndex(client, mappingFile, lifecycleAlias); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = DeleteIndex(client, lifecycleAlias) }()
+
+	_, err := Reindex(client, ReindexConfig{Alias: lifecycleAlias, MappingFile: mappingFile, Version: 1}, loadTestAddresses(1))
+	if !errors.Is(err, ErrAliasIsIndex) {
+		t.Errorf("Expected ErrAliasIsIndex. actual: %v", err)
+	}
+}
+
+// Helper test functions
+func loadTestAddresses(count int) LoadFunc {
+	return func(indexName string) (BulkStats, error) {
+		addresses := make(chan mapping.EsAddress)
+		go func() {
+			for i := 0; i < count; i++ {
+				address := buildTestEsAddress(100 + i)
+				address.Id = CalculateId(Address{Number: address.Number, Street: address.Street})
+				addresses <- address
+			}
+			close(addresses)
+		}()
+		return BulkIndexEs(client, indexName, addresses)
+	}
+}
+
+func deleteLifecycleIndices(t *testing.T) {
+	if err := DeleteOldVersions(client, lifecycleAlias, ""); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -1,15 +1,35 @@
 package data
 
-// Address is a normalized address with the minimum required fields.
+// Address is a normalized address with the minimum required fields. SourceId is the record ID from the data source,
+// when the source has one. UnitDesignator and UnitId hold the secondary unit of unit level points, for example APT and
+// 4B, and are empty for building points. HouseNumber is the house number as written, such as 1234 1/2 or 12-14, with
+// Number its numeric part, or the low end of a range. The fraction, letter and range fields are empty when the number
+// has none. ZipCityMismatch is set when the city is not one the ZIP code is known by. Municipality, Township, Ward and
+// CensusTract name the areas containing the address, and are empty when it is outside them or the areas were not loaded.
+// Pin is the 14 digit PIN of the parcel the address is on, from the source or the parcel containing it.
 type Address struct {
-	Number       int
-	StreetPrefix string
-	Street       string
-	StreetSuffix string
-	City         string
-	State        string
-	Zip5         string
-	ZipLast4     string
-	Longitude    float64
-	Latitude     float64
+	SourceId        string
+	Number          int
+	HouseNumber     string
+	NumberFraction  string
+	NumberSuffix    string
+	NumberLow       int
+	NumberHigh      int
+	StreetPrefix    string
+	Street          string
+	StreetSuffix    string
+	UnitDesignator  string
+	UnitId          string
+	City            string
+	State           string
+	Zip5            string
+	ZipLast4        string
+	Longitude       float64
+	Latitude        float64
+	ZipCityMismatch bool
+	Municipality    string
+	Township        string
+	Ward            string
+	CensusTract     string
+	Pin             string
 }
@@ -1,72 +1,87 @@
 package data
 
 import (
+	"cook-county-geocoder/shared/housenumber"
+	"cook-county-geocoder/shared/projection"
+	"cook-county-geocoder/shared/standardize"
+	"cook-county-geocoder/shared/zipcity"
 	"encoding/csv"
-	"errors"
 	"fmt"
 	"io"
 	"log"
+	"math"
 	"os"
-	"regexp"
 	"strconv"
 	"strings"
 )
 
 // Represents an unvalidated, csv row.
 type RawData struct {
+	sourceId     string
 	number       string
 	streetPrefix string
 	street       string
 	streetSuffix string
-	city         string
-	state        string
-	zip5         string
-	zipLast4     string
-	longitude    string
-	latitude     string
+	// unit is the secondary unit as written in the source, for example "APT 4B", or empty for building points.
+	unit      string
+	city      string
+	state     string
+	zip5      string
+	zipLast4  string
+	longitude string
+	latitude  string
+	// statePlaneX and statePlaneY are State Plane Illinois East coordinates in feet, used when a source has no latitude
+	// and longitude.
+	statePlaneX string
+	statePlaneY string
+	// pin is the 14 digit parcel PIN, or empty when the source has none.
+	pin string
 }
 
-func CsvReader(fileName string, normalizedOutput chan<- Address, errorOutput chan<- string, complete chan <- bool) {
+// CsvReader streams each valid row of the CSV file to normalizedOutput and each rejected row to rejectedOutput. Columns
+// are found by header name as described by the schema. Both channels are closed when the reader returns, so callers
+// can range over them. A FileError is returned if the file cannot be opened or read, and a HeaderError if the header is
+// missing columns the schema requires. Addresses outside the boundary are rejected, and a nil boundary skips the check.
+// Missing cities are filled in from zipCities and cities it does not accept for the ZIP code are flagged. A nil table
+// leaves cities as they are.
+func CsvReader(fileName string, schema Schema, boundary *Boundary, zipCities zipcity.Table, normalizedOutput chan<- Address, rejectedOutput chan<- RejectedRow) error {
+	defer close(normalizedOutput)
+	defer close(rejectedOutput)
+
 	csvFile, err := os.Open(fileName)
 	if err != nil {
-		log.Fatal("Could not read CSV file: ", fileName, err)
+		return &FileError{Path: fileName, Err: err}
 	}
+	defer csvFile.Close()
 	reader := csv.NewReader(csvFile)
 
 	// Check header
 	headers, err := reader.Read()
 	if err != nil {
-		log.Fatal(err)
+		return &FileError{Path: fileName, Line: 1, Err: err}
 	}
-	rawHeader := buildCookCountyRaw(headers)
-	err = checkCookCountyHeaders(rawHeader)
+	columns, err := schema.Resolve(headers)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 
 	normalizedAddressCount := 0
 	errorCount := 0
+	line := 1
 
 	for {
 		// Read each record from csv
 		record, err := reader.Read()
+		line++
 		if err == io.EOF {
 			break
 		}
 		if err != nil {
-			log.Fatal(err)
-		}
-		rawCsv := buildCookCountyRaw(record)
-		err = checkRequiredFields(rawCsv)
-		if err != nil {
-			errorOutput <- fmt.Sprintf("Error: %s | Original line: %s", err.Error(), strings.Join(record, ","))
-			errorCount++
-			continue
+			return &FileError{Path: fileName, Line: line, Err: err}
 		}
-
-		normalizedAddress, err := transformRawToAddress(rawCsv)
+		normalizedAddress, err := validateRaw(columns.Raw(record), boundary, zipCities)
 		if err != nil {
-			errorOutput <- fmt.Sprintf("Error: %s | Original line: %s", err.Error(), strings.Join(record, ","))
+			rejectedOutput <- newRejectedRow(line, record, err)
 			errorCount++
 			continue
 		}
@@ -77,25 +92,22 @@ func CsvReader(fileName string, normalizedOutput chan<- Address, errorOutput cha
 
 	log.Printf("Finished writing %d addresses to output channel\n", normalizedAddressCount)
 	log.Printf("Total errors: %d\n", errorCount)
-	complete <- true
-	close(errorOutput)
-	close(normalizedOutput)
-	close(complete)
+	return nil
 }
 
 // Validation functions for all data sources.
 
-// checkRequiredFields inspects required fields and combines missing fields into a single error message.
+// checkRequiredFields inspects required fields and combines missing fields into a single RowError.
 // Future enhancement- some required fields may be recoverable (state, city, zip5) by combining with other sources.
 func checkRequiredFields(data RawData) error {
 	missingFields := make([]string, 0, 7)
 	if data.number == "" {
 		missingFields = append(missingFields, "number")
 	}
-	if data.street == ""  {
+	if data.street == "" {
 		missingFields = append(missingFields, "street")
 	}
-	if data.city == ""  {
+	if data.city == "" {
 		missingFields = append(missingFields, "city")
 	}
 	if data.state == "" {
@@ -104,115 +116,122 @@ func checkRequiredFields(data RawData) error {
 	if data.zip5 == "" {
 		missingFields = append(missingFields, "zip5")
 	}
-	if data.longitude =="" {
+	hasStatePlane := data.statePlaneX != "" && data.statePlaneY != ""
+	if data.longitude == "" && !hasStatePlane {
 		missingFields = append(missingFields, "longitude")
 	}
-	if data.latitude == "" {
+	if data.latitude == "" && !hasStatePlane {
 		missingFields = append(missingFields, "latitude")
 	}
 	if len(missingFields) > 0 {
 		missing := fmt.Sprintf("missing required fields- %s raw data struct- %v", strings.Join(missingFields, ","), data)
-		return errors.New(missing)
+		return &RowError{Category: CategoryMissingField, Fields: missingFields, Message: missing}
 	}
 	return nil
 }
 
-// transformRawToAddress converts RawData strings to the desired data type, eagerly returning errors. If all validation
-// is passed, then an Address is returned.
+// transformRawToAddress converts RawData strings to the desired data type, eagerly returning RowErrors. If all
+// validation is passed, then an Address is returned. Street prefixes and suffixes are standardized to USPS abbreviations.
+// House numbers keep their fraction, letter or range so 1234 1/2 and 1234A are not indexed over 1234. Rows without a
+// latitude and longitude are located by their State Plane coordinates. Coordinates must be finite numbers, latitude
+// within ±90 and longitude within ±180. The ingest boundary is checked separately.
 func transformRawToAddress(raw RawData) (Address, error) {
-	const MaxLocation = 90.0
-	const MinLocation = -90.0
+	const MaxLatitude = 90.0
+	const MaxLongitude = 180.0
 
-	// Calling cleanseAddressNumber works with a single Cook County data source. This will likely need to be moved when adding
-	// more data sources
-	cleanNumber := cleanseAddressNumber(raw.number)
-	num, err := strconv.Atoi(cleanNumber)
+	raw, err := projectStatePlane(raw)
 	if err != nil {
-		return Address{}, fmt.Errorf("could not parse address number to int. cleansed number- %s raw number- %s full struct- %v", cleanNumber, raw.number, raw)
+		return Address{}, err
 	}
 
-	long, err := strconv.ParseFloat(raw.longitude, 64)
+	number, err := housenumber.Parse(raw.number)
 	if err != nil {
-		return Address{}, fmt.Errorf("could not parse address longitude to float64. longitude- %s full struct- %v", raw.longitude, raw)
+		return Address{}, rowErrorf(CategoryBadNumber, "number", "could not parse address number. raw number- %s full struct- %v", raw.number, raw)
 	}
 
-	if long > MaxLocation || long < MinLocation {
-		return Address{}, fmt.Errorf("longitude is outside of logical range. longitude- %f full struct- %v", long, raw)
+	long, err := parseCoordinate(raw.longitude)
+	if err != nil {
+		return Address{}, rowErrorf(CategoryBadCoordinate, "longitude", "could not parse address longitude to float64. longitude- %s full struct- %v", raw.longitude, raw)
 	}
 
-	lat, err := strconv.ParseFloat(raw.latitude, 64)
+	if long > MaxLongitude || long < -MaxLongitude {
+		return Address{}, rowErrorf(CategoryOutOfRange, "longitude", "longitude is outside of logical range. longitude- %f full struct- %v", long, raw)
+	}
+
+	lat, err := parseCoordinate(raw.latitude)
 	if err != nil {
-		return Address{}, fmt.Errorf("could not parse address latitude to float64. latitude- %s full struct- %v", raw.latitude, raw)
+		return Address{}, rowErrorf(CategoryBadCoordinate, "latitude", "could not parse address latitude to float64. latitude- %s full struct- %v", raw.latitude, raw)
 	}
 
-	if lat > MaxLocation || lat < MinLocation {
-		return Address{}, fmt.Errorf("latitude is outside of logical range. latitude- %f full struct- %v", lat, raw)
+	if lat > MaxLatitude || lat < -MaxLatitude {
+		return Address{}, rowErrorf(CategoryOutOfRange, "latitude", "latitude is outside of logical range. latitude- %f full struct- %v", lat, raw)
 	}
 
+	unitDesignator, unitId := standardize.SplitUnit(raw.unit)
+
 	validAddress := Address{
-		Number:       num,
-		StreetPrefix: raw.streetPrefix,
-		Street:       raw.street,
-		StreetSuffix: raw.streetSuffix,
-		City:         raw.city,
-		State:        raw.state,
-		Zip5:         raw.zip5,
-		ZipLast4:     raw.zipLast4,
-		Longitude:    long,
-		Latitude:     lat,
+		SourceId:       raw.sourceId,
+		Number:         number.Number,
+		HouseNumber:    number.Full,
+		NumberFraction: number.Fraction,
+		NumberSuffix:   number.Suffix,
+		NumberLow:      number.Low,
+		NumberHigh:     number.High,
+		StreetPrefix:   standardize.DirectionalOrOriginal(raw.streetPrefix),
+		Street:         raw.street,
+		StreetSuffix:   standardize.SuffixOrOriginal(raw.streetSuffix),
+		UnitDesignator: unitDesignator,
+		UnitId:         unitId,
+		City:           raw.city,
+		State:          raw.state,
+		Zip5:           raw.zip5,
+		ZipLast4:       raw.zipLast4,
+		Longitude:      long,
+		Latitude:       lat,
+		Pin:            raw.pin,
 	}
 	return validAddress, nil
 }
 
-// cleanseAddressNumber attempts to get a string that can be parsed to an int based on known data issues.
-func cleanseAddressNumber(input string) string {
-	// Some address have a fraction or decimal in them. Take the first part.
-	partialAddressSplit := func(r rune) bool {
-		return r == '.' || r == ' '
+// projectStatePlane fills in the latitude and longitude from the State Plane Illinois East coordinates when the row only
+// has the latter.
+func projectStatePlane(raw RawData) (RawData, error) {
+	if raw.longitude != "" || raw.latitude != "" || raw.statePlaneX == "" || raw.statePlaneY == "" {
+		return raw, nil
 	}
-	numberHead := strings.FieldsFunc(input, partialAddressSplit)[0]
+	x, err := parseCoordinate(raw.statePlaneX)
+	if err != nil {
+		return raw, rowErrorf(CategoryBadCoordinate, "state_plane_x", "could not parse state plane x to float64. x- %s full struct- %v", raw.statePlaneX, raw)
+	}
+	y, err := parseCoordinate(raw.statePlaneY)
+	if err != nil {
+		return raw, rowErrorf(CategoryBadCoordinate, "state_plane_y", "could not parse state plane y to float64. y- %s full struct- %v", raw.statePlaneY, raw)
+	}
+	lat, lon := projection.IllinoisEast.Inverse(x, y)
+	if !isFinite(lat) || !isFinite(lon) {
+		return raw, &RowError{Category: CategoryBadCoordinate, Fields: []string{"state_plane_x", "state_plane_y"}, Message: fmt.Sprintf("state plane coordinates do not project to a location. x- %f y- %f full struct- %v", x, y, raw)}
+	}
+	raw.latitude = strconv.FormatFloat(lat, 'f', -1, 64)
+	raw.longitude = strconv.FormatFloat(lon, 'f', -1, 64)
+	return raw, nil
+}
 
-	r := regexp.MustCompile("[a-zA-Z]|-")
-	lettersRemoved := r.ReplaceAllString(numberHead, "")
-	return strings.TrimSpace(lettersRemoved)
+// parseCoordinate parses a coordinate, rejecting NaN and infinities, which ParseFloat accepts but no range check catches.
+func parseCoordinate(raw string) (float64, error) {
+	value, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return 0, err
+	}
+	if !isFinite(value) {
+		return 0, fmt.Errorf("coordinate %s is not a finite number", raw)
+	}
+	return value, nil
 }
 
-// Data source specific data extraction functions. Could be generic with data source specific parameters- cross that bridge
-// when adding new source.
-
-// checkCookCountyHeaders is called on the header row of the Cook County CSV to make sure columns are correctly mapped.
-func checkCookCountyHeaders(data RawData) error {
-	expected := RawData{
-		number:       "ADDRNOCOM",
-		streetPrefix: "STNAMEPRD",
-		street:       "STNAME",
-		streetSuffix: "STNAMEPOT",
-		city:         "USPSPN",
-		state:        "USPSST",
-		zip5:         "ZIP5",
-		zipLast4:     "ZIP4",
-		longitude:    "XPOSITION",
-		latitude:     "YPOSITION",
-	}
-	if expected == data {
-		return nil
-	}
-	return fmt.Errorf("error mapping header columns. expected: %v actual :%v", expected, data)
+func isFinite(value float64) bool {
+	return !math.IsNaN(value) && !math.IsInf(value, 0)
 }
 
-// buildCookCountyRaw contains the column mapping for the Cook County CSV data. It must be called on both the header
-// and non-header row to ensure data integrity.
-func buildCookCountyRaw(row []string) RawData {
-	return RawData{
-		number:       strings.TrimSpace(row[3]),
-		streetPrefix: strings.TrimSpace(row[4]),
-		street:       strings.TrimSpace(row[5]),
-		streetSuffix: strings.TrimSpace(row[6]),
-		city:         strings.TrimSpace(row[10]),
-		state:        strings.TrimSpace(row[12]),
-		zip5:         strings.TrimSpace(row[13]),
-		zipLast4:     strings.TrimSpace(row[14]),
-		longitude:    strings.TrimSpace(row[21]),
-		latitude:     strings.TrimSpace(row[22]),
-	}
+func rowErrorf(category string, field string, format string, args ...interface{}) *RowError {
+	return &RowError{Category: category, Fields: []string{field}, Message: fmt.Sprintf(format, args...)}
 }
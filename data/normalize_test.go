@@ -1,6 +1,11 @@
 package data
 
 import (
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -41,95 +46,12 @@ func TestCheckRequiredFieldsWithMissingFields(t *testing.T) {
 	}
 }
 
-func TestCookCountyHeadersWithValidInput(t *testing.T) {
-	validHeaders := RawData{
-		number:       "ADDRNOCOM",
-		streetPrefix: "STNAMEPRD",
-		street:       "STNAME",
-		streetSuffix: "STNAMEPOT",
-		city:         "USPSPN",
-		state:        "USPSST",
-		zip5:         "ZIP5",
-		zipLast4:     "ZIP4",
-		longitude:    "XPOSITION",
-		latitude:     "YPOSITION",
-	}
-	err := checkCookCountyHeaders(validHeaders)
-	if err != nil {
-		t.Errorf("Expected no errors when headers are in the correct location. Found %v", err)
-	}
-}
-
-func TestCookCountyHeadersInvalidHeaderLocation(t *testing.T) {
-	inputMissingRequired := RawData{
-		number:       "numberInvalid",
-		streetPrefix: "streetPrefixInvalid",
-		street:       "streetInvalid",
-		streetSuffix: "streetSuffixInvalid",
-		city:         "cityInvalid",
-		state:        "stateInvalid",
-		zip5:         "zip5Invalid",
-		zipLast4:     "zipLast4Invalid",
-		longitude:    "longitudeInvalid",
-		latitude:     "latitudeInvalid",
-	}
-	if checkCookCountyHeaders(inputMissingRequired) == nil {
-		t.Errorf("Expected error when passed unexpected headers. No error returned.")
-	}
-}
-
-func TestBuildCookCountryRow(t *testing.T) {
-	input := []string{
-		"0",
-		"1",
-		"2",
-		"number",
-		"streetPrefix",
-		"street",
-		"streetSuffix",
-		"7",
-		"8",
-		"9",
-		"city",
-		"11",
-		"state",
-		"zip5",
-		"zipLast4",
-		"15",
-		"16",
-		"17",
-		"18",
-		"19",
-		"20",
-		"longitude  ",
-		"latitude  ",
-		"23",
-		"24",
-	}
-	expected := RawData{
-		number:       "number",
-		streetPrefix: "streetPrefix",
-		street:       "street",
-		streetSuffix: "streetSuffix",
-		city:         "city",
-		state:        "state",
-		zip5:         "zip5",
-		zipLast4:     "zipLast4",
-		longitude:    "longitude",
-		latitude:     "latitude",
-	}
-
-	actual := buildCookCountyRaw(input)
-	if actual != expected {
-		t.Errorf("Error building raw data struct. actual: %v expected: %v", actual, expected)
-	}
-}
-
 func TestTransformRawToAddressWithValidInput(t *testing.T) {
 	validRawInput := buildRawData("1234", "57.684512", "-15.24568")
 
 	expected := Address{
 		Number:       1234,
+		HouseNumber:  "1234",
 		StreetPrefix: "streetPrefix",
 		Street:       "street",
 		StreetSuffix: "streetSuffix",
@@ -152,6 +74,19 @@ func TestTransformRawToAddressWithValidInput(t *testing.T) {
 	}
 }
 
+func TestTransformRawToAddressStandardizesPrefixAndSuffix(t *testing.T) {
+	raw := buildRawData("1234", "57.684512", "-15.24568")
+	raw.streetPrefix = "NORTH"
+	raw.streetSuffix = "AVENUE"
+
+	actual, err := transformRawToAddress(raw)
+	if err != nil {
+		t.Errorf("Expected no errors with valid input. Found %v", err)
+	}
+	if actual.StreetPrefix != "N" || actual.StreetSuffix != "AVE" {
+		t.Errorf("Expected USPS abbreviations. actual: %v", actual)
+	}
+}
 
 func TestTransformRawToAddressWithNumericParsingErrors(t *testing.T) {
 	rawDataBadNumber := buildRawData("bad number", "57.684512", "-15.24568")
@@ -191,56 +126,48 @@ func TestTransformRawToAddressWithOutOfRangeLatLongValues(t *testing.T) {
 		t.Errorf("Expected error when passed a RawData struct with higher out of range latitude. No error returned.")
 	}
 
-	rawDataLowLongitude := buildRawData("1234", "-90.0001", "-15.24568")
+	rawDataLowLongitude := buildRawData("1234", "-180.0001", "-15.24568")
 	_, err = transformRawToAddress(rawDataLowLongitude)
 
 	if err == nil {
 		t.Errorf("Expected error when passed a RawData struct with lower out of range longitude. No error returned.")
 	}
 
-	rawDataHighLongitude := buildRawData("1234", "90.0001", "15.24568")
+	rawDataHighLongitude := buildRawData("1234", "180.0001", "15.24568")
 	_, err = transformRawToAddress(rawDataHighLongitude)
 
 	if err == nil {
 		t.Errorf("Expected error when passed a RawData struct with higher out of range longitude. No error returned.")
 	}
-}
-
-func TestCleanseDataSourceUsingSplitCriteria(t *testing.T) {
-	rawWithSpace := "12345 1/2"
-	if cleanseAddressNumber(rawWithSpace) != "12345" {
-		t.Errorf("Expected string before space.")
-	}
 
-	rawWithDecimal := "999.5"
-	if cleanseAddressNumber(rawWithDecimal) != "999" {
-		t.Errorf("Expected string before decimal.")
+	// Longitudes beyond ±90 are valid. The ingest boundary rejects the ones outside the county.
+	if _, err = transformRawToAddress(buildRawData("1234", "-95.5", "41.5")); err != nil {
+		t.Errorf("Expected no errors for longitude -95.5. Found %v", err)
 	}
 }
 
-func TestCleanseDataSourceWithLetters(t *testing.T) {
-	rawWithLetters := "12345ABCdef GHIJ  "
-	if cleanseAddressNumber(rawWithLetters) != "12345" {
-		t.Errorf("Expected string without letters and spaces.")
+func TestTransformRawToAddressKeepsHouseNumberParts(t *testing.T) {
+	cases := map[string]Address{
+		"1234 1/2":  {Number: 1234, HouseNumber: "1234 1/2", NumberFraction: "1/2"},
+		"1234A":     {Number: 1234, HouseNumber: "1234A", NumberSuffix: "A"},
+		"12-14":     {Number: 12, HouseNumber: "12-14", NumberLow: 12, NumberHigh: 14},
+		"77777    ": {Number: 77777, HouseNumber: "77777"},
 	}
-}
-
-func TestCleanseDataSourceWithValid(t *testing.T) {
-	valid := "12345"
-	if cleanseAddressNumber(valid) != "12345" {
-		t.Errorf("Expected string to be unaltered.")
+	for input, expected := range cases {
+		actual, err := transformRawToAddress(buildRawData(input, "57.684512", "-15.24568"))
+		if err != nil {
+			t.Errorf("Expected no errors for %q. Found %v", input, err)
+		}
+		if actual.Number != expected.Number || actual.HouseNumber != expected.HouseNumber || actual.NumberFraction != expected.NumberFraction ||
+			actual.NumberSuffix != expected.NumberSuffix || actual.NumberLow != expected.NumberLow || actual.NumberHigh != expected.NumberHigh {
+			t.Errorf("Error parsing house number %q. actual: %v", input, actual)
+		}
 	}
 
-	validWithSpaces := "77777    "
-	if cleanseAddressNumber(validWithSpaces) != "77777" {
-		t.Errorf("Expected string to be trimmed.")
-	}
-
-	// This is likely a fat finger in the data, and intended to be a half address. It shouldn't be altered as it cannot
-	// be assumed that this is an input error.
-	validCannotBeSplit := "777771/2"
-	if cleanseAddressNumber(validCannotBeSplit) != validCannotBeSplit {
-		t.Errorf("Expected string to be unaltered as fractional address cannot be assumed.")
+	// This is likely a fat finger in the data, and intended to be a half address. It cannot be assumed, so the row is
+	// rejected.
+	if _, err := transformRawToAddress(buildRawData("777771/2", "57.684512", "-15.24568")); err == nil {
+		t.Errorf("Expected an error for a fraction that is not set apart from the number.")
 	}
 }
 
@@ -258,3 +185,99 @@ func buildRawData(number string, longitude string, latitude string) RawData {
 		latitude:     latitude,
 	}
 }
+
+func TestCsvReaderWithMissingFile(t *testing.T) {
+	normalized := make(chan Address)
+	rejected := make(chan RejectedRow)
+	err := CsvReader("does_not_exist.csv", cookCountySchema(t), nil, nil, normalized, rejected)
+
+	var fileErr *FileError
+	if !errors.As(err, &fileErr) || !os.IsNotExist(fileErr.Err) {
+		t.Errorf("Expected a FileError for a missing file. actual: %v", err)
+	}
+	if _, open := <-normalized; open {
+		t.Errorf("Expected output channel to be closed.")
+	}
+}
+
+func TestCsvReaderWithBadHeader(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "bad_header.csv")
+	header := strings.Repeat("column,", 24) + "column\n"
+	if err := os.WriteFile(fileName, []byte(header), 0666); err != nil {
+		t.Fatalf("Could not write test file %s", err)
+	}
+
+	err := CsvReader(fileName, cookCountySchema(t), nil, nil, make(chan Address), make(chan RejectedRow))
+	var headerErr *HeaderError
+	if !errors.As(err, &headerErr) {
+		t.Errorf("Expected a HeaderError for unexpected columns. actual: %v", err)
+	}
+
+	if err := os.WriteFile(fileName, []byte("a,b,c\n"), 0666); err != nil {
+		t.Fatalf("Could not write test file %s", err)
+	}
+	err = CsvReader(fileName, cookCountySchema(t), nil, nil, make(chan Address), make(chan RejectedRow))
+	if !errors.As(err, &headerErr) {
+		t.Errorf("Expected a HeaderError for a short header. actual: %v", err)
+	}
+}
+
+func TestCsvReaderReadsUnitColumns(t *testing.T) {
+	unit := cookCountyRow("1234", "MADISON", "CHICAGO", "-87.65", "41.88")
+	unit[23], unit[24] = "Apartment", "4B"
+	idOnly := cookCountyRow("1234", "MADISON", "CHICAGO", "-87.65", "41.88")
+	idOnly[24] = "200"
+	fileName := writeCookCountyCsv(t, cookCountyRow("1234", "MADISON", "CHICAGO", "-87.65", "41.88"), unit, idOnly)
+
+	normalized := make(chan Address, 10)
+	if err := CsvReader(fileName, cookCountySchema(t), nil, nil, normalized, make(chan RejectedRow, 10)); err != nil {
+		t.Fatalf("Expected no errors reading CSV. Found %v", err)
+	}
+	expected := [][2]string{{"", ""}, {"APT", "4B"}, {"#", "200"}}
+	for _, e := range expected {
+		address := <-normalized
+		if address.UnitDesignator != e[0] || address.UnitId != e[1] {
+			t.Errorf("Unexpected unit. actual: %s %s expected: %v", address.UnitDesignator, address.UnitId, e)
+		}
+	}
+}
+
+func TestTransformRawToAddressRejectsNonFiniteCoordinates(t *testing.T) {
+	var rowErr *RowError
+	for _, coordinates := range [][2]string{{"NaN", "41.5"}, {"-87.6", "NaN"}, {"Inf", "41.5"}, {"-87.6", "-Inf"}} {
+		_, err := transformRawToAddress(buildRawData("1234", coordinates[0], coordinates[1]))
+		if !errors.As(err, &rowErr) || rowErr.Category != CategoryBadCoordinate {
+			t.Errorf("Expected a bad coordinate error for %v. actual: %v", coordinates, err)
+		}
+	}
+
+	for _, statePlane := range [][2]string{{"NaN", "1900395.0"}, {"1176371.8", "+Inf"}} {
+		raw := buildRawData("1234", "", "")
+		raw.statePlaneX, raw.statePlaneY = statePlane[0], statePlane[1]
+		_, err := transformRawToAddress(raw)
+		if !errors.As(err, &rowErr) || rowErr.Category != CategoryBadCoordinate {
+			t.Errorf("Expected a bad coordinate error for State Plane %v. actual: %v", statePlane, err)
+		}
+	}
+}
+
+func TestTransformRawToAddressProjectsStatePlane(t *testing.T) {
+	raw := buildRawData("1234", "", "")
+	raw.statePlaneX, raw.statePlaneY = "1176371.8", "1900395.0"
+	if err := checkRequiredFields(raw); err != nil {
+		t.Errorf("Expected State Plane coordinates to stand in for latitude and longitude. Found %v", err)
+	}
+	actual, err := transformRawToAddress(raw)
+	if err != nil {
+		t.Fatalf("Expected no errors with State Plane coordinates. Found %v", err)
+	}
+	if math.Abs(actual.Latitude-41.88204) > 1e-5 || math.Abs(actual.Longitude+87.62782) > 1e-5 {
+		t.Errorf("Expected State and Madison. actual: %f,%f", actual.Latitude, actual.Longitude)
+	}
+
+	raw.statePlaneX = "east"
+	var rowErr *RowError
+	if _, err := transformRawToAddress(raw); !errors.As(err, &rowErr) || rowErr.Category != CategoryBadCoordinate {
+		t.Errorf("Expected a bad coordinate error. actual: %v", err)
+	}
+}
@@ -0,0 +1,132 @@
+package data
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Rejection categories. Every rejected row has exactly one.
+const (
+	CategoryMissingField  = "missing_field"
+	CategoryBadNumber     = "bad_number"
+	CategoryBadCoordinate = "bad_coordinate"
+	CategoryOutOfRange    = "out_of_range"
+	// CategoryOutsideBoundary is a valid location outside the ingest boundary.
+	CategoryOutsideBoundary = "outside_boundary"
+	// CategorySwappedCoordinates is a location inside the boundary only with its latitude and longitude swapped, when
+	// swapped coordinates are not corrected.
+	CategorySwappedCoordinates = "swapped_coordinates"
+)
+
+// RowError is returned by row validation. Fields names the RawData fields that caused the rejection.
+type RowError struct {
+	Category string
+	Fields   []string
+	Message  string
+}
+
+func (e *RowError) Error() string {
+	return e.Message
+}
+
+// RejectedRow is a source row that failed validation, along with why it failed. Line is the 1 based row number in the
+// source, counting the header.
+type RejectedRow struct {
+	Line     int      `json:"line"`
+	Category string   `json:"category"`
+	Fields   []string `json:"fields"`
+	Message  string   `json:"message"`
+	Record   []string `json:"record"`
+}
+
+// newRejectedRow builds a RejectedRow from a validation error. Errors that are not RowErrors are not expected, but are
+// kept with an empty category rather than dropped.
+func newRejectedRow(line int, record []string, err error) RejectedRow {
+	rejected := RejectedRow{Line: line, Message: err.Error(), Record: record}
+	if rowErr, ok := err.(*RowError); ok {
+		rejected.Category = rowErr.Category
+		rejected.Fields = rowErr.Fields
+	}
+	return rejected
+}
+
+// RejectWriter writes rejected rows in a machine readable format.
+type RejectWriter interface {
+	Write(row RejectedRow) error
+	// Flush writes any buffered rows. It does not close the underlying writer.
+	Flush() error
+}
+
+// NewRejectWriter returns a RejectWriter for the format, either "jsonl" or "csv".
+func NewRejectWriter(format string, w io.Writer) (RejectWriter, error) {
+	switch format {
+	case "jsonl":
+		return &jsonlRejectWriter{encoder: json.NewEncoder(w)}, nil
+	case "csv":
+		return &csvRejectWriter{writer: csv.NewWriter(w)}, nil
+	default:
+		return nil, fmt.Errorf("unknown rejected row format %s", format)
+	}
+}
+
+// jsonlRejectWriter writes one JSON object per line.
+type jsonlRejectWriter struct {
+	encoder *json.Encoder
+}
+
+func (j *jsonlRejectWriter) Write(row RejectedRow) error {
+	return j.encoder.Encode(row)
+}
+
+func (j *jsonlRejectWriter) Flush() error {
+	return nil
+}
+
+// csvRejectWriter writes line, category, fields and message columns followed by the original record's columns.
+// Fields are separated by ";". Rows have a variable number of columns, so readers should not enforce a field count.
+type csvRejectWriter struct {
+	writer        *csv.Writer
+	headerWritten bool
+}
+
+var rejectCsvHeader = []string{"line", "category", "fields", "message", "record"}
+
+func (c *csvRejectWriter) Write(row RejectedRow) error {
+	if !c.headerWritten {
+		if err := c.writer.Write(rejectCsvHeader); err != nil {
+			return err
+		}
+		c.headerWritten = true
+	}
+	columns := append([]string{strconv.Itoa(row.Line), row.Category, strings.Join(row.Fields, ";"), row.Message}, row.Record...)
+	return c.writer.Write(columns)
+}
+
+func (c *csvRejectWriter) Flush() error {
+	c.writer.Flush()
+	return c.writer.Error()
+}
+
+// WriteRejects writes every row from the channel and returns the count of rows per category. The channel is drained
+// even if writing fails, so the producer is never blocked.
+func WriteRejects(rows <-chan RejectedRow, writer RejectWriter) (map[string]int, error) {
+	counts := make(map[string]int)
+	var writeErr error
+	for row := range rows {
+		counts[row.Category]++
+		if writeErr == nil {
+			writeErr = writer.Write(row)
+		}
+	}
+	if writeErr != nil {
+		return counts, fmt.Errorf("could not write rejected row: %w", writeErr)
+	}
+	if err := writer.Flush(); err != nil {
+		return counts, fmt.Errorf("could not write rejected row: %w", err)
+	}
+	return counts, nil
+}
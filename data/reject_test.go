@@ -0,0 +1,183 @@
+package data
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCsvReaderEmitsStructuredRejectedRows(t *testing.T) {
+	fileName := writeCookCountyCsv(t,
+		cookCountyRow("1234", "MADISON", "CHICAGO", "-87.65", "41.88"),
+		cookCountyRow("", "MADISON", "", "-87.65", "41.88"),
+		cookCountyRow("ABC", "MADISON", "CHICAGO", "-87.65", "41.88"),
+		cookCountyRow("1234", "MADISON", "CHICAGO", "west", "41.88"),
+		cookCountyRow("1234", "MADISON", "CHICAGO", "-87.65", "141.88"),
+	)
+
+	normalized := make(chan Address, 10)
+	rejected := make(chan RejectedRow, 10)
+	if err := CsvReader(fileName, cookCountySchema(t), nil, nil, normalized, rejected); err != nil {
+		t.Fatalf("Expected no errors reading CSV. Found %v", err)
+	}
+
+	var rows []RejectedRow
+	for row := range rejected {
+		rows = append(rows, row)
+	}
+	if len(normalized) != 1 || len(rows) != 4 {
+		t.Fatalf("Expected 1 valid and 4 rejected rows. actual: %d valid %v rejected", len(normalized), rows)
+	}
+
+	expected := []struct {
+		line     int
+		category string
+		fields   string
+	}{
+		{3, CategoryMissingField, "number,city"},
+		{4, CategoryBadNumber, "number"},
+		{5, CategoryBadCoordinate, "longitude"},
+		{6, CategoryOutOfRange, "latitude"},
+	}
+	for i, e := range expected {
+		row := rows[i]
+		if row.Line != e.line || row.Category != e.category || strings.Join(row.Fields, ",") != e.fields {
+			t.Errorf("Unexpected rejected row. actual: %d %s %v expected: %v", row.Line, row.Category, row.Fields, e)
+		}
+		if len(row.Record) != cookCountyRowWidth {
+			t.Errorf("Expected the raw record to be kept. actual: %v", row.Record)
+		}
+	}
+}
+
+func TestJsonlRejectWriter(t *testing.T) {
+	var out bytes.Buffer
+	writer, err := NewRejectWriter("jsonl", &out)
+	if err != nil {
+		t.Fatalf("Could not build writer %s", err)
+	}
+	counts, err := WriteRejects(rejectedRows(), writer)
+	if err != nil {
+		t.Fatalf("Expected no errors writing rows. Found %v", err)
+	}
+	if counts[CategoryMissingField] != 1 || counts[CategoryBadNumber] != 1 {
+		t.Errorf("Unexpected counts %v", counts)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected one line per row. actual: %s", out.String())
+	}
+	var decoded RejectedRow
+	if err := json.Unmarshal([]byte(lines[1]), &decoded); err != nil {
+		t.Fatalf("Could not decode line %s", err)
+	}
+	if decoded.Line != 9 || decoded.Category != CategoryBadNumber || decoded.Record[1] != "b" {
+		t.Errorf("Unexpected decoded row %v", decoded)
+	}
+}
+
+func TestCsvRejectWriter(t *testing.T) {
+	var out bytes.Buffer
+	writer, err := NewRejectWriter("csv", &out)
+	if err != nil {
+		t.Fatalf("Could not build writer %s", err)
+	}
+	if _, err := WriteRejects(rejectedRows(), writer); err != nil {
+		t.Fatalf("Expected no errors writing rows. Found %v", err)
+	}
+
+	reader := csv.NewReader(&out)
+	reader.FieldsPerRecord = -1
+	rows, err := reader.ReadAll()
+	if err != nil {
+		t.Fatalf("Could not read output %s", err)
+	}
+	if len(rows) != 3 || strings.Join(rows[0], ",") != "line,category,fields,message,record" {
+		t.Fatalf("Expected a header and 2 rows. actual: %v", rows)
+	}
+	if strings.Join(rows[1], ",") != "2,missing_field,number;city,missing,a,b" {
+		t.Errorf("Unexpected row %v", rows[1])
+	}
+}
+
+func TestNewRejectWriterWithUnknownFormat(t *testing.T) {
+	if _, err := NewRejectWriter("xml", &bytes.Buffer{}); err == nil {
+		t.Errorf("Expected error for an unknown format. No error returned.")
+	}
+}
+
+func rejectedRows() <-chan RejectedRow {
+	rows := make(chan RejectedRow, 2)
+	rows <- RejectedRow{Line: 2, Category: CategoryMissingField, Fields: []string{"number", "city"}, Message: "missing", Record: []string{"a", "b"}}
+	rows <- RejectedRow{Line: 9, Category: CategoryBadNumber, Fields: []string{"number"}, Message: "bad", Record: []string{"a", "b"}}
+	close(rows)
+	return rows
+}
+
+// cookCountyRowWidth is the width of the test rows, with the Cook County columns at the positions of the county export
+// and the two unit columns last.
+const cookCountyRowWidth = 25
+
+// cookCountySchema loads the Cook County source schema.
+func cookCountySchema(t *testing.T) Schema {
+	schema, err := LoadSchema("schemas/cook_county.json")
+	if err != nil {
+		t.Fatalf("Could not load the Cook County schema %s", err)
+	}
+	return schema
+}
+
+// cookCountyRow builds a Cook County CSV row with the columns read by the Cook County schema.
+func cookCountyRow(number string, street string, city string, longitude string, latitude string) []string {
+	row := make([]string, cookCountyRowWidth)
+	row[3] = number
+	row[4] = "W"
+	row[5] = street
+	row[6] = "ST"
+	row[10] = city
+	row[12] = "IL"
+	row[13] = "60607"
+	row[21] = longitude
+	row[22] = latitude
+	return row
+}
+
+// cookCountyHeader builds the header row matching cookCountyRow.
+func cookCountyHeader() []string {
+	header := make([]string, cookCountyRowWidth)
+	for i := range header {
+		header[i] = "column"
+	}
+	header[3] = "ADDRNOCOM"
+	header[4] = "STNAMEPRD"
+	header[5] = "STNAME"
+	header[6] = "STNAMEPOT"
+	header[10] = "USPSPN"
+	header[12] = "USPSST"
+	header[13] = "ZIP5"
+	header[14] = "ZIP4"
+	header[21] = "XPOSITION"
+	header[22] = "YPOSITION"
+	header[23] = "SubAddType"
+	header[24] = "SubAddId"
+	return header
+}
+
+// writeCookCountyCsv writes a Cook County CSV with a valid header to a temporary file.
+func writeCookCountyCsv(t *testing.T, rows ...[]string) string {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	_ = writer.Write(cookCountyHeader())
+	_ = writer.WriteAll(rows)
+
+	fileName := filepath.Join(t.TempDir(), "addresses.csv")
+	if err := os.WriteFile(fileName, buf.Bytes(), 0666); err != nil {
+		t.Fatalf("Could not write test file %s", err)
+	}
+	return fileName
+}
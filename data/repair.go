@@ -0,0 +1,140 @@
+package data
+
+import (
+	"bufio"
+	"cook-county-geocoder/shared/housenumber"
+	"cook-county-geocoder/shared/zipcity"
+	"encoding/json"
+	"log"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Repair rule names, reported with the number of rows each one recovered.
+const (
+	RuleFillCityState = "fill_city_state"
+	RuleNumberPunct   = "number_punctuation"
+)
+
+// RepairReport summarizes a reprocessing run.
+type RepairReport struct {
+	Read          int
+	Recovered     int
+	StillRejected int
+	ByRule        map[string]int
+}
+
+// RejectReader streams rows from a JSONL rejected row file, as written by the jsonl RejectWriter. The output channel is
+// closed when the reader returns.
+func RejectReader(fileName string, output chan<- RejectedRow) error {
+	defer close(output)
+
+	file, err := os.Open(fileName)
+	if err != nil {
+		return &FileError{Path: fileName, Err: err}
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		var row RejectedRow
+		if err := json.Unmarshal(scanner.Bytes(), &row); err != nil {
+			return &FileError{Path: fileName, Line: line, Err: err}
+		}
+		output <- row
+	}
+	if err := scanner.Err(); err != nil {
+		return &FileError{Path: fileName, Line: line, Err: err}
+	}
+	return nil
+}
+
+// Reprocess applies the repair rules to previously rejected rows and re-validates them. The rows are read with the
+// columns of the source they were rejected from. Recovered rows are sent to normalizedOutput and rows that still fail are
+// sent to rejectedOutput with their new rejection reason. Repaired rows are checked against the boundary like any other
+// row. Both outputs are closed when the input is exhausted.
+func Reprocess(rejected <-chan RejectedRow, columns Columns, zipCities zipcity.Table, boundary *Boundary, normalizedOutput chan<- Address, rejectedOutput chan<- RejectedRow) RepairReport {
+	defer close(normalizedOutput)
+	defer close(rejectedOutput)
+
+	report := RepairReport{ByRule: make(map[string]int)}
+	for row := range rejected {
+		report.Read++
+		if len(row.Record) < columns.width {
+			report.StillRejected++
+			rejectedOutput <- row
+			continue
+		}
+
+		raw, rules := repairRaw(columns.Raw(row.Record), zipCities)
+		address, err := validateRaw(raw, boundary, zipCities)
+		if err != nil {
+			report.StillRejected++
+			rejectedOutput <- newRejectedRow(row.Line, row.Record, err)
+			continue
+		}
+
+		report.Recovered++
+		for _, rule := range rules {
+			report.ByRule[rule]++
+		}
+		normalizedOutput <- address
+	}
+
+	log.Printf("Reprocessed %d rejected rows. Recovered %d, still rejected %d, by rule %v\n", report.Read, report.Recovered, report.StillRejected, report.ByRule)
+	return report
+}
+
+// validateRaw checks a row read from any source. The city and state are filled in from the ZIP code when missing, and
+// the location is checked against the boundary.
+func validateRaw(raw RawData, boundary *Boundary, zipCities zipcity.Table) (Address, error) {
+	raw, _ = fillCityState(raw, zipCities)
+	if err := checkRequiredFields(raw); err != nil {
+		return Address{}, err
+	}
+	address, err := transformRawToAddress(raw)
+	if err != nil {
+		return Address{}, err
+	}
+	address.ZipCityMismatch = !zipCities.Accepts(address.Zip5, address.City)
+	return boundary.check(address)
+}
+
+// repairRaw applies every repair rule that matches and returns the names of the rules used.
+func repairRaw(raw RawData, zipCities zipcity.Table) (RawData, []string) {
+	rules := make([]string, 0, 2)
+
+	if filled, ok := fillCityState(raw, zipCities); ok {
+		raw = filled
+		rules = append(rules, RuleFillCityState)
+	}
+
+	if _, err := housenumber.Parse(raw.number); err == nil {
+		return raw, rules
+	}
+	if number, rule, ok := recoverNumber(raw.number); ok {
+		raw.number = number
+		rules = append(rules, rule)
+	}
+	return raw, rules
+}
+
+// #1234, 1234#, 1234,
+var numberPunctuationPattern = regexp.MustCompile(`^[^\w]*(\d+)[^\w]*$`)
+
+// recoverNumber extracts a usable house number from known malformed values. ok is false when no rule applies. It is only
+// used on numbers housenumber.Parse rejects, so ranges and fractions rejected before they were supported are recovered
+// as they are.
+func recoverNumber(number string) (string, string, bool) {
+	if match := numberPunctuationPattern.FindStringSubmatch(number); match != nil && match[1] != strings.TrimSpace(number) {
+		return match[1], RuleNumberPunct, true
+	}
+	return number, "", false
+}
@@ -0,0 +1,123 @@
+package data
+
+import (
+	"cook-county-geocoder/shared/zipcity"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRecoverNumber(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected string
+		rule     string
+	}{
+		{"#1234", "1234", RuleNumberPunct},
+		{"1234,", "1234", RuleNumberPunct},
+	}
+	for _, c := range cases {
+		actual, rule, ok := recoverNumber(c.input)
+		if !ok || actual != c.expected || rule != c.rule {
+			t.Errorf("Error recovering %q. actual: %s %s %t expected: %s %s", c.input, actual, rule, ok, c.expected, c.rule)
+		}
+	}
+
+	for _, unrecoverable := range []string{"1/2", "ABC", "1234"} {
+		if _, _, ok := recoverNumber(unrecoverable); ok {
+			t.Errorf("Expected %q to not be recovered.", unrecoverable)
+		}
+	}
+}
+
+func TestRepairRawLeavesValidHouseNumbers(t *testing.T) {
+	for _, number := range []string{"1234-1236", "1234½", "1234 1/2"} {
+		raw, rules := repairRaw(buildRawData(number, "-87.65", "41.88"), zipcity.Table{})
+		if raw.number != number || len(rules) != 0 {
+			t.Errorf("Expected %q to be left alone. actual: %s %v", number, raw.number, rules)
+		}
+	}
+}
+
+func TestRepairRawFillsCityAndStateFromZip(t *testing.T) {
+	zipCities := zipcity.Table{"60607": {City: "CHICAGO", State: "IL"}}
+	raw := buildRawData("1234", "-87.65", "41.88")
+	raw.city = ""
+	raw.state = ""
+	raw.zip5 = "60607"
+
+	repaired, rules := repairRaw(raw, zipCities)
+	if repaired.city != "CHICAGO" || repaired.state != "IL" {
+		t.Errorf("Expected city and state from the ZIP. actual: %v", repaired)
+	}
+	if len(rules) != 1 || rules[0] != RuleFillCityState {
+		t.Errorf("Expected fill rule to be reported. actual: %v", rules)
+	}
+
+	raw.zip5 = "99999"
+	if _, rules := repairRaw(raw, zipCities); len(rules) != 0 {
+		t.Errorf("Expected no repairs for an unknown ZIP. actual: %v", rules)
+	}
+}
+
+func TestReprocessRecoversFixableRows(t *testing.T) {
+	rejected := make(chan RejectedRow, 3)
+	rejected <- RejectedRow{Line: 2, Category: CategoryMissingField, Record: cookCountyRow("1234", "MADISON", "", "-87.65", "41.88")}
+	rejected <- RejectedRow{Line: 3, Category: CategoryBadNumber, Record: cookCountyRow("1234½", "MADISON", "CHICAGO", "-87.65", "41.88")}
+	rejected <- RejectedRow{Line: 4, Category: CategoryBadNumber, Record: cookCountyRow("1/2", "MADISON", "CHICAGO", "-87.65", "41.88")}
+	close(rejected)
+
+	normalized := make(chan Address, 3)
+	stillRejected := make(chan RejectedRow, 3)
+	columns, err := cookCountySchema(t).Resolve(cookCountyHeader())
+	if err != nil {
+		t.Fatalf("Could not resolve columns %s", err)
+	}
+	report := Reprocess(rejected, columns, zipcity.Table{"60607": {City: "CHICAGO", State: "IL"}}, nil, normalized, stillRejected)
+
+	if report.Read != 3 || report.Recovered != 2 || report.StillRejected != 1 {
+		t.Errorf("Unexpected report %+v", report)
+	}
+	if report.ByRule[RuleFillCityState] != 1 || len(report.ByRule) != 1 {
+		t.Errorf("Unexpected rule counts %v", report.ByRule)
+	}
+
+	first := <-normalized
+	if first.City != "CHICAGO" || first.Number != 1234 {
+		t.Errorf("Unexpected recovered address %v", first)
+	}
+	// A half address rejected before fractions were supported is now valid as it is.
+	second := <-normalized
+	if second.HouseNumber != "1234 1/2" {
+		t.Errorf("Expected the fraction to be kept. actual: %v", second)
+	}
+	row := <-stillRejected
+	if row.Line != 4 || row.Category != CategoryBadNumber {
+		t.Errorf("Unexpected still rejected row %v", row)
+	}
+}
+
+func TestRejectReaderReadsJsonl(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "rejected.jsonl")
+	file, err := os.Create(fileName)
+	if err != nil {
+		t.Fatalf("Could not create test file %s", err)
+	}
+	writer, _ := NewRejectWriter("jsonl", file)
+	if _, err := WriteRejects(rejectedRows(), writer); err != nil {
+		t.Fatalf("Could not write test file %s", err)
+	}
+	_ = file.Close()
+
+	rows := make(chan RejectedRow, 10)
+	if err := RejectReader(fileName, rows); err != nil {
+		t.Fatalf("Expected no errors reading rejected rows. Found %v", err)
+	}
+	var lines []int
+	for row := range rows {
+		lines = append(lines, row.Line)
+	}
+	if len(lines) != 2 || lines[0] != 2 || lines[1] != 9 {
+		t.Errorf("Unexpected rows read back. lines: %v", lines)
+	}
+}
@@ -0,0 +1,212 @@
+package data
+
+import (
+	"cook-county-geocoder/shared/parcel"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// Source schemas describe the columns of a CSV source by header name, so columns can move or be added without breaking
+// ingest and new sources only need a schema file. The Cook County schema is in data/schemas/cook_county.json.
+
+// Schema maps the header names of a CSV source to RawData fields.
+type Schema struct {
+	Name    string         `json:"name"`
+	Columns []SchemaColumn `json:"columns"`
+}
+
+// SchemaColumn maps one header to a field. Several columns may map to the same field, such as a unit designator and
+// identifier, and their values are joined with a space in schema order. Transforms are applied in order to the trimmed
+// value. Default is used when the value is empty or an optional column is missing.
+type SchemaColumn struct {
+	Header     string   `json:"header"`
+	Field      string   `json:"field"`
+	Optional   bool     `json:"optional,omitempty"`
+	Default    string   `json:"default,omitempty"`
+	Transforms []string `json:"transforms,omitempty"`
+}
+
+// rawFields are the field names a schema can map to.
+var rawFields = map[string]func(*RawData) *string{
+	"source_id":     func(r *RawData) *string { return &r.sourceId },
+	"number":        func(r *RawData) *string { return &r.number },
+	"street_prefix": func(r *RawData) *string { return &r.streetPrefix },
+	"street":        func(r *RawData) *string { return &r.street },
+	"street_suffix": func(r *RawData) *string { return &r.streetSuffix },
+	"unit":          func(r *RawData) *string { return &r.unit },
+	"city":          func(r *RawData) *string { return &r.city },
+	"state":         func(r *RawData) *string { return &r.state },
+	"zip5":          func(r *RawData) *string { return &r.zip5 },
+	"zip_last_4":    func(r *RawData) *string { return &r.zipLast4 },
+	"longitude":     func(r *RawData) *string { return &r.longitude },
+	"latitude":      func(r *RawData) *string { return &r.latitude },
+	"state_plane_x": func(r *RawData) *string { return &r.statePlaneX },
+	"state_plane_y": func(r *RawData) *string { return &r.statePlaneY },
+	"pin":           func(r *RawData) *string { return &r.pin },
+}
+
+// transforms are the value transforms a schema column can apply. zip5 and zip4 split a ZIP+4 such as 60607-1234, and pin
+// writes a parcel PIN as 14 digits, leaving it empty when it is not a PIN.
+var transforms = map[string]func(string) string{
+	"upper":  strings.ToUpper,
+	"digits": digits,
+	"pin": func(value string) string {
+		pin, _ := parcel.NormalizePin(value)
+		return pin
+	},
+	"zip5": func(value string) string {
+		if d := digits(value); len(d) > 5 {
+			return d[:5]
+		}
+		return digits(value)
+	},
+	"zip4": func(value string) string {
+		if d := digits(value); len(d) > 5 {
+			return d[5:]
+		}
+		return ""
+	},
+}
+
+func digits(value string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, value)
+}
+
+// LoadSchema reads a schema file and checks its fields and transforms. A FileError is returned if the file cannot be
+// read or is not a valid schema.
+func LoadSchema(path string) (Schema, error) {
+	file, err := os.ReadFile(path)
+	if err != nil {
+		return Schema{}, &FileError{Path: path, Err: err}
+	}
+	var schema Schema
+	if err := json.Unmarshal(file, &schema); err != nil {
+		return Schema{}, &FileError{Path: path, Err: err}
+	}
+	if err := schema.validate(); err != nil {
+		return Schema{}, &FileError{Path: path, Err: err}
+	}
+	return schema, nil
+}
+
+func (s Schema) validate() error {
+	if len(s.Columns) == 0 {
+		return fmt.Errorf("schema %s has no columns", s.Name)
+	}
+	for _, column := range s.Columns {
+		if strings.TrimSpace(column.Header) == "" {
+			return fmt.Errorf("schema %s has a column without a header", s.Name)
+		}
+		if _, ok := rawFields[column.Field]; !ok {
+			return fmt.Errorf("schema %s maps %s to unknown field %q", s.Name, column.Header, column.Field)
+		}
+		for _, name := range column.Transforms {
+			if _, ok := transforms[name]; !ok {
+				return fmt.Errorf("schema %s has unknown transform %q on %s", s.Name, name, column.Header)
+			}
+		}
+	}
+	return nil
+}
+
+// Columns is a Schema resolved against the header row of a file.
+type Columns struct {
+	columns []resolvedColumn
+	// width is the fewest values a row needs to hold every column found in the header.
+	width int
+}
+
+type resolvedColumn struct {
+	SchemaColumn
+	// index is the position of the column in the row, or -1 for a missing optional column.
+	index int
+}
+
+// Resolve finds each schema column in the header row. Headers are matched ignoring case and surrounding space. A
+// HeaderError listing every missing required column is returned if any are not found.
+func (s Schema) Resolve(header []string) (Columns, error) {
+	positions := make(map[string]int, len(header))
+	for i, name := range header {
+		// Excel and ArcGIS exports may start the file with a byte order mark.
+		name = strings.ToUpper(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if _, ok := positions[name]; !ok {
+			positions[name] = i
+		}
+	}
+
+	resolved := Columns{columns: make([]resolvedColumn, 0, len(s.Columns))}
+	missing := make([]string, 0)
+	for _, column := range s.Columns {
+		index, ok := positions[strings.ToUpper(strings.TrimSpace(column.Header))]
+		if !ok {
+			if !column.Optional && column.Default == "" {
+				missing = append(missing, column.Header)
+			}
+			index = -1
+		}
+		if index+1 > resolved.width {
+			resolved.width = index + 1
+		}
+		resolved.columns = append(resolved.columns, resolvedColumn{SchemaColumn: column, index: index})
+	}
+	if len(missing) > 0 {
+		return Columns{}, &HeaderError{Schema: s.Name, Missing: missing}
+	}
+	return resolved, nil
+}
+
+// Raw maps a row to RawData. Values past the end of a short row are empty.
+func (c Columns) Raw(row []string) RawData {
+	var raw RawData
+	for _, column := range c.columns {
+		value := ""
+		if column.index >= 0 && column.index < len(row) {
+			value = strings.TrimSpace(row[column.index])
+		}
+		for _, name := range column.Transforms {
+			value = transforms[name](value)
+		}
+		if value == "" {
+			value = column.Default
+		}
+		if value == "" {
+			continue
+		}
+		field := rawFields[column.Field](&raw)
+		*field = strings.TrimSpace(*field + " " + value)
+	}
+	return raw
+}
+
+// IsRemoteSource reports whether the source is an http or https URL, read as an ArcGIS REST layer query, rather than a
+// CSV file.
+func IsRemoteSource(source string) bool {
+	return strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://")
+}
+
+// ReadColumns reads the header row of a CSV file and resolves the schema against it. An ArcGIS layer URL has no header
+// row, so it returns a FileError wrapping ErrRemoteSource.
+func ReadColumns(fileName string, schema Schema) (Columns, error) {
+	if IsRemoteSource(fileName) {
+		return Columns{}, &FileError{Path: fileName, Err: ErrRemoteSource}
+	}
+	csvFile, err := os.Open(fileName)
+	if err != nil {
+		return Columns{}, &FileError{Path: fileName, Err: err}
+	}
+	defer csvFile.Close()
+	header, err := csv.NewReader(csvFile).Read()
+	if err != nil {
+		return Columns{}, &FileError{Path: fileName, Line: 1, Err: err}
+	}
+	return schema.Resolve(header)
+}
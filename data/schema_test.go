@@ -0,0 +1,119 @@
+package data
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestResolveFindsColumnsByHeaderName(t *testing.T) {
+	schema := Schema{Name: "test", Columns: []SchemaColumn{
+		{Header: "number", Field: "number"},
+		{Header: "Street", Field: "street", Transforms: []string{"upper"}},
+		{Header: "unit type", Field: "unit", Optional: true},
+		{Header: "unit", Field: "unit", Optional: true},
+		{Header: "zip", Field: "zip5", Transforms: []string{"zip5"}},
+		{Header: "zip", Field: "zip_last_4", Transforms: []string{"zip4"}},
+		{Header: "state", Field: "state", Default: "IL"},
+		{Header: "city", Field: "city", Optional: true},
+	}}
+	columns, err := schema.Resolve([]string{"\ufeffZIP", " Unit ", "STREET", "Number", "Unit Type"})
+	if err != nil {
+		t.Fatalf("Expected no errors resolving columns. Found %v", err)
+	}
+	if columns.width != 5 {
+		t.Errorf("Expected the row width to cover every column found. actual: %d", columns.width)
+	}
+
+	actual := columns.Raw([]string{"60607-1234", "4B", " Madison ", "1200", "APT"})
+	expected := RawData{number: "1200", street: "MADISON", unit: "APT 4B", zip5: "60607", zipLast4: "1234", state: "IL"}
+	if actual != expected {
+		t.Errorf("Error mapping row. actual: %+v expected: %+v", actual, expected)
+	}
+
+	if short := columns.Raw([]string{"60607"}); short.zip5 != "60607" || short.number != "" {
+		t.Errorf("Expected values past the end of a short row to be empty. actual: %+v", short)
+	}
+}
+
+func TestPinTransform(t *testing.T) {
+	schema := Schema{Name: "test", Columns: []SchemaColumn{{Header: "pin", Field: "pin", Transforms: []string{"pin"}}}}
+	columns, err := schema.Resolve([]string{"PIN"})
+	if err != nil {
+		t.Fatalf("Expected no errors resolving columns. Found %v", err)
+	}
+	cases := map[string]string{"17-08-437-012-1001": "17084370121001", "1708437012": "17084370120000", "N/A": ""}
+	for input, expected := range cases {
+		if actual := columns.Raw([]string{input}); actual.pin != expected {
+			t.Errorf("Error transforming PIN %q. actual: %q expected: %q", input, actual.pin, expected)
+		}
+	}
+}
+
+func TestResolveReportsMissingColumns(t *testing.T) {
+	schema := Schema{Name: "test", Columns: []SchemaColumn{
+		{Header: "number", Field: "number"},
+		{Header: "street", Field: "street"},
+		{Header: "unit", Field: "unit", Optional: true},
+	}}
+	_, err := schema.Resolve([]string{"street"})
+	var headerErr *HeaderError
+	if !errors.As(err, &headerErr) || strings.Join(headerErr.Missing, ",") != "number" || headerErr.Schema != "test" {
+		t.Errorf("Expected a HeaderError listing the missing required column. actual: %v", err)
+	}
+}
+
+func TestReadColumnsRejectsUrls(t *testing.T) {
+	schema, err := LoadSchema("schemas/cook_county.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = ReadColumns("https://gis.cookcountyil.gov/arcgis/rest/services/AddressPoints/MapServer/0/query", schema)
+	var fileErr *FileError
+	if !errors.As(err, &fileErr) || !errors.Is(err, ErrRemoteSource) {
+		t.Errorf("Expected a FileError wrapping ErrRemoteSource for a URL. actual: %v", err)
+	}
+}
+
+func TestLoadSchema(t *testing.T) {
+	schema := cookCountySchema(t)
+	if _, err := schema.Resolve(cookCountyHeader()); err != nil {
+		t.Errorf("Expected the Cook County schema to resolve against the county header. Found %v", err)
+	}
+
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"field.json":     `{"name": "bad", "columns": [{"header": "A", "field": "apartment"}]}`,
+		"transform.json": `{"name": "bad", "columns": [{"header": "A", "field": "city", "transforms": ["title"]}]}`,
+		"empty.json":     `{"name": "bad"}`,
+		"json.json":      `{"name": `,
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0666); err != nil {
+			t.Fatalf("Could not write test file %s", err)
+		}
+		var fileErr *FileError
+		if _, err := LoadSchema(path); !errors.As(err, &fileErr) {
+			t.Errorf("Expected a FileError for %s. actual: %v", name, err)
+		}
+	}
+}
+
+func TestCsvReaderWithReorderedColumns(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "reordered.csv")
+	content := "YPOSITION,XPOSITION,ZIP5,USPSST,USPSPN,STNAME,ADDRNOCOM,EXTRA\n41.88,-87.65,60607,IL,CHICAGO,MADISON,1200,x\n"
+	if err := os.WriteFile(fileName, []byte(content), 0666); err != nil {
+		t.Fatalf("Could not write test file %s", err)
+	}
+
+	normalized := make(chan Address, 1)
+	if err := CsvReader(fileName, cookCountySchema(t), nil, nil, normalized, make(chan RejectedRow, 1)); err != nil {
+		t.Fatalf("Expected no errors reading CSV. Found %v", err)
+	}
+	address := <-normalized
+	if address.Number != 1200 || address.Street != "MADISON" || address.Latitude != 41.88 || address.Longitude != -87.65 {
+		t.Errorf("Unexpected address %v", address)
+	}
+}
@@ -1,23 +1,93 @@
 package data
 
-import "cook-county-geocoder/shared/mapping"
+import (
+	"cook-county-geocoder/shared/mapping"
+	"cook-county-geocoder/shared/phonetic"
+	"cook-county-geocoder/shared/standardize"
+	"crypto/sha1"
+	"encoding/hex"
+	"strconv"
+	"strings"
+)
 
 // Transformer is a simple file for now. This layer is separated to house more complex scoring logic and combining
 // data from different sources.
+//
+// ToEsAddress expects standardized prefixes and suffixes and adds their long forms as search aliases, along with the
+// street's phonetic key and the single line address used for autocomplete.
 func ToEsAddress(address Address) mapping.EsAddress {
-	return mapping.EsAddress{
-		Number:       address.Number,
-		StreetPrefix: address.StreetPrefix,
-		Street:       address.Street,
-		StreetSuffix: address.StreetSuffix,
-		City:         address.City,
-		State:        address.State,
-		Zip5:         address.Zip5,
-		ZipLast4:     address.ZipLast4,
-		LatLong:      mapping.LatLong{Latitude: address.Latitude, Longitude: address.Longitude},
+	esAddress := mapping.EsAddress{
+		Id:                CalculateId(address),
+		Number:            address.Number,
+		HouseNumber:       address.HouseNumber,
+		NumberFraction:    address.NumberFraction,
+		NumberSuffix:      address.NumberSuffix,
+		NumberLow:         address.NumberLow,
+		NumberHigh:        address.NumberHigh,
+		StreetPrefix:      address.StreetPrefix,
+		StreetPrefixAlias: standardize.DirectionalLongForm(address.StreetPrefix),
+		Street:            address.Street,
+		StreetPhonetic:    phonetic.Key(address.Street),
+		StreetSuffix:      address.StreetSuffix,
+		StreetSuffixAlias: standardize.SuffixLongForm(address.StreetSuffix),
+		UnitDesignator:    address.UnitDesignator,
+		UnitId:            address.UnitId,
+		City:              address.City,
+		State:             address.State,
+		Zip5:              address.Zip5,
+		ZipLast4:          address.ZipLast4,
+		LatLong:           mapping.LatLong{Latitude: address.Latitude, Longitude: address.Longitude},
+		ZipCityMismatch:   address.ZipCityMismatch,
+		Municipality:      address.Municipality,
+		Township:          address.Township,
+		Ward:              address.Ward,
+		CensusTract:       address.CensusTract,
+		Pin:               address.Pin,
 	}
+	esAddress.FullAddress = mapping.FormatAddress(esAddress)
+	return esAddress
 }
 
-//func CalculateId(address Address) {
-//
-//}
\ No newline at end of file
+// EnrichAddresses tags addresses with the areas containing them as they arrive and closes the output once the input is
+// closed.
+func EnrichAddresses(layers AreaLayers, addresses <-chan Address, enriched chan<- Address) {
+	for address := range addresses {
+		enriched <- layers.Tag(address)
+	}
+	close(enriched)
+}
+
+// ToEsAddresses converts addresses as they arrive and closes the output once the input is closed.
+func ToEsAddresses(addresses <-chan Address, esAddresses chan<- mapping.EsAddress) {
+	for address := range addresses {
+		esAddresses <- ToEsAddress(address)
+	}
+	close(esAddresses)
+}
+
+// CalculateId builds a stable document ID from the normalized address parts and the source record ID, if any.
+// Re-indexing the same address overwrites the existing document instead of adding a duplicate. City is left out
+// because it can be corrected or filled in after the fact, while the ZIP code already pins down the location. The unit is
+// only added for unit level points, and the house number as written only when it is not a plain integer, so plain
+// building points keep the IDs they had before units and fractions were indexed.
+func CalculateId(address Address) string {
+	parts := []string{
+		address.SourceId,
+		strconv.Itoa(address.Number),
+		address.StreetPrefix,
+		address.Street,
+		address.StreetSuffix,
+		address.Zip5,
+	}
+	if address.HouseNumber != "" && address.HouseNumber != strconv.Itoa(address.Number) {
+		parts = append(parts, address.HouseNumber)
+	}
+	if address.UnitDesignator != "" || address.UnitId != "" {
+		parts = append(parts, address.UnitDesignator, address.UnitId)
+	}
+	for i, part := range parts {
+		parts[i] = strings.Join(strings.Fields(strings.ToUpper(part)), " ")
+	}
+	sum := sha1.Sum([]byte(strings.Join(parts, "|")))
+	return hex.EncodeToString(sum[:])
+}
@@ -20,17 +20,84 @@ func TestToEsAddressTransformsAnAddress(t *testing.T) {
 	}
 	actual := ToEsAddress(valid)
 	expected := mapping.EsAddress{
-		Number:       1234,
-		StreetPrefix: "streetPrefix",
-		Street:       "street",
-		StreetSuffix: "streetSuffix",
-		City:         "city",
-		State:        "state",
-		Zip5:         "zip5",
-		ZipLast4:     "zipLast4",
-		LatLong:      mapping.LatLong{Latitude: -15.24568, Longitude: 57.684512},
+		Id:             CalculateId(valid),
+		Number:         1234,
+		StreetPrefix:   "streetPrefix",
+		Street:         "street",
+		StreetPhonetic: "S363",
+		StreetSuffix:   "streetSuffix",
+		City:           "city",
+		State:          "state",
+		Zip5:           "zip5",
+		ZipLast4:       "zipLast4",
+		LatLong:        mapping.LatLong{Latitude: -15.24568, Longitude: 57.684512},
+		FullAddress:    "1234 streetPrefix street streetSuffix, city, state zip5",
 	}
 	if actual != expected {
 		t.Errorf("Error transforming Address to EsAddress. actual: %v expected: %v", actual, expected)
 	}
 }
+
+func TestToEsAddressAddsLongFormAliases(t *testing.T) {
+	address := Address{StreetPrefix: "SW", Street: "HIGHLAND", StreetSuffix: "AVE"}
+	actual := ToEsAddress(address)
+	if actual.StreetPrefixAlias != "SOUTHWEST" || actual.StreetSuffixAlias != "AVENUE" {
+		t.Errorf("Expected long form aliases. actual: %v", actual)
+	}
+}
+
+func TestToEsAddressesClosesOutput(t *testing.T) {
+	addresses := make(chan Address, 2)
+	addresses <- Address{Number: 1}
+	addresses <- Address{Number: 2}
+	close(addresses)
+
+	esAddresses := make(chan mapping.EsAddress)
+	go ToEsAddresses(addresses, esAddresses)
+
+	var numbers []int
+	for esAddress := range esAddresses {
+		numbers = append(numbers, esAddress.Number)
+	}
+	if len(numbers) != 2 || numbers[0] != 1 || numbers[1] != 2 {
+		t.Errorf("Expected both addresses in order before the output closed. actual: %v", numbers)
+	}
+}
+
+func TestCalculateIdIsStableAcrossFormatting(t *testing.T) {
+	address := Address{Number: 1200, StreetPrefix: "W", Street: "MADISON", StreetSuffix: "ST", City: "CHICAGO", Zip5: "60607"}
+	reformatted := Address{Number: 1200, HouseNumber: "1200", StreetPrefix: "w", Street: " Madison ", StreetSuffix: "St", City: "", Zip5: "60607", Latitude: 41.88}
+
+	id := CalculateId(address)
+	if id != CalculateId(address) {
+		t.Errorf("Expected the same ID on every call.")
+	}
+	if id != CalculateId(reformatted) {
+		t.Errorf("Expected case, spacing, city and coordinates to not change the ID.")
+	}
+	if len(id) != 40 {
+		t.Errorf("Expected a hex encoded SHA-1. actual: %s", id)
+	}
+}
+
+func TestCalculateIdDistinguishesAddresses(t *testing.T) {
+	address := Address{Number: 1200, StreetPrefix: "W", Street: "MADISON", StreetSuffix: "ST", Zip5: "60607"}
+	id := CalculateId(address)
+
+	otherNumber := address
+	otherNumber.Number = 1202
+	otherPrefix := address
+	otherPrefix.StreetPrefix = "E"
+	withSourceId := address
+	withSourceId.SourceId = "42"
+	withUnit := address
+	withUnit.UnitDesignator, withUnit.UnitId = "APT", "4B"
+	half := address
+	half.HouseNumber = "1200 1/2"
+
+	for _, other := range []Address{otherNumber, otherPrefix, withSourceId, withUnit, half} {
+		if CalculateId(other) == id {
+			t.Errorf("Expected a different ID for %v", other)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package data
+
+import "cook-county-geocoder/shared/zipcity"
+
+// BuildZipCities runs a reader over a source and builds the ZIP code city table from its accepted rows, by majority
+// vote per ZIP. The reader is the same one used to ingest the source, so a source is read twice when the table is used
+// on its own ingest.
+func BuildZipCities(read func(chan<- Address, chan<- RejectedRow) error) (zipcity.Table, error) {
+	normalized := make(chan Address, 1000)
+	rejected := make(chan RejectedRow, 1000)
+	readErr := make(chan error, 1)
+	go func() { readErr <- read(normalized, rejected) }()
+	go func() {
+		for range rejected {
+		}
+	}()
+
+	votes := make(zipcity.Votes)
+	for address := range normalized {
+		votes.Add(address.Zip5, address.City, address.State)
+	}
+	if err := <-readErr; err != nil {
+		return nil, err
+	}
+	return votes.Table(), nil
+}
+
+// fillCityState fills in a missing city or state with the preferred city and state of the ZIP code. ok is false when
+// nothing is missing or the ZIP code is not in the table.
+func fillCityState(raw RawData, zipCities zipcity.Table) (RawData, bool) {
+	if (raw.city != "" && raw.state != "") || raw.zip5 == "" {
+		return raw, false
+	}
+	cities, ok := zipCities[raw.zip5]
+	if !ok {
+		return raw, false
+	}
+	if raw.city == "" {
+		raw.city = cities.City
+	}
+	if raw.state == "" {
+		raw.state = cities.State
+	}
+	return raw, true
+}
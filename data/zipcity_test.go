@@ -0,0 +1,64 @@
+package data
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBuildZipCitiesByMajority(t *testing.T) {
+	fileName := writeCookCountyCsv(t,
+		cookCountyRow("1200", "MADISON", "CICERO", "-87.6579", "41.8817"),
+		cookCountyRow("1201", "MADISON", "CHICAGO", "-87.6581", "41.8817"),
+		cookCountyRow("1202", "MADISON", "CHICAGO", "-87.6579", "41.8818"),
+		cookCountyRow("1203", "MADISON", "", "-87.6581", "41.8818"),
+	)
+	zipCities, err := BuildZipCities(func(normalized chan<- Address, rejected chan<- RejectedRow) error {
+		return CsvReader(fileName, cookCountySchema(t), nil, nil, normalized, rejected)
+	})
+	if err != nil {
+		t.Fatalf("Expected no errors. Found %v", err)
+	}
+	if cities := zipCities["60607"]; cities.City != "CHICAGO" || cities.State != "IL" || len(cities.Acceptable) != 0 {
+		t.Errorf("Expected the majority city. actual: %v", zipCities)
+	}
+
+	_, err = BuildZipCities(func(normalized chan<- Address, rejected chan<- RejectedRow) error {
+		return CsvReader("does_not_exist.csv", cookCountySchema(t), nil, nil, normalized, rejected)
+	})
+	if err == nil {
+		t.Errorf("Expected the reader error to be returned.")
+	}
+}
+
+func TestCsvReaderFillsAndFlagsCities(t *testing.T) {
+	fileName := writeCookCountyCsv(t,
+		cookCountyRow("1200", "MADISON", "", "-87.6579", "41.8817"),
+		cookCountyRow("1201", "MADISON", "EVANSTON", "-87.6581", "41.8817"),
+		cookCountyRow("1202", "MADISON", "chicago", "-87.6579", "41.8818"),
+	)
+	zipCities, err := BuildZipCities(func(normalized chan<- Address, rejected chan<- RejectedRow) error {
+		return CsvReader(fileName, cookCountySchema(t), nil, nil, normalized, rejected)
+	})
+	if err != nil {
+		t.Fatalf("Expected no errors. Found %v", err)
+	}
+
+	normalized := make(chan Address, 10)
+	rejected := make(chan RejectedRow, 10)
+	if err := CsvReader(fileName, cookCountySchema(t), nil, zipCities, normalized, rejected); err != nil {
+		t.Fatalf("Expected no errors. Found %v", err)
+	}
+	var cities []string
+	var mismatches []bool
+	for address := range normalized {
+		cities = append(cities, address.City)
+		mismatches = append(mismatches, address.ZipCityMismatch)
+	}
+	// EVANSTON and CHICAGO tie, and CHICAGO sorts first.
+	if !reflect.DeepEqual(cities, []string{"CHICAGO", "EVANSTON", "chicago"}) || !reflect.DeepEqual(mismatches, []bool{false, true, false}) {
+		t.Errorf("Expected the missing city to be filled and EVANSTON flagged. actual: %v %v", cities, mismatches)
+	}
+	if row, ok := <-rejected; ok {
+		t.Errorf("Expected no rejected rows. actual: %v", row)
+	}
+}
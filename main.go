@@ -1,58 +1,302 @@
 package main
 
 import (
+	"context"
+	"cook-county-geocoder/api"
 	"cook-county-geocoder/data"
+	"cook-county-geocoder/shared/grid"
 	"cook-county-geocoder/shared/mapping"
-	"fmt"
+	"cook-county-geocoder/shared/zipcity"
+	"errors"
+	"flag"
+	"github.com/elastic/go-elasticsearch/v7"
+	"log"
+	"net/http"
 	"os"
+	"strings"
+	"time"
+)
+
+const (
+	// channelBuffer bounds how many records can wait between ingest stages.
+	channelBuffer = 1000
+	// sourceRequestTimeout bounds each page request to a remote source.
+	sourceRequestTimeout = time.Minute
 )
 
 func main() {
-	// TODO Use ENV VARs or CLI to toggle
-	//dataModule()
-	apiModule()
+	mode := flag.String("mode", "api", "Module to run: api, batch, data, reprocess or grid")
+	esHosts := flag.String("es", "http://localhost:9200", "Comma separated Elasticsearch hosts")
+	indexName := flag.String("index", "address", "Address alias name. Data mode loads a new versioned index behind it")
+	listenAddr := flag.String("listen", ":8080", "Address for the API server to listen on")
+	batchIn := flag.String("in", "", "Batch mode input CSV")
+	batchOut := flag.String("out", "", "Batch mode output CSV. Defaults to stdout")
+	addressCol := flag.String("address-col", "address", "Batch mode address column name")
+	cityCol := flag.String("city-col", "", "Batch mode city column name")
+	zipCol := flag.String("zip-col", "", "Batch mode ZIP code column name")
+	sourceFile := flag.String("source", "data/Address_Points.csv", "Data and reprocess mode source CSV. Data mode also takes an ArcGIS REST layer query URL")
+	schemaFile := flag.String("schema", "data/schemas/cook_county.json", "Data and reprocess mode source CSV column schema")
+	boundaryFile := flag.String("boundary", "data/boundaries/cook_county.geojson", "Data and reprocess mode GeoJSON boundary every address must fall inside. Empty skips the check")
+	areasFile := flag.String("areas", "data/boundaries/areas.json", "Data and reprocess mode list of GeoJSON area layers to tag addresses with. Empty skips tagging")
+	fixSwapped := flag.Bool("fix-swapped", true, "Data and reprocess mode swaps latitude and longitude back when only the swapped point is inside the boundary")
+	rejectsFile := flag.String("rejects", "data/rejected_rows.jsonl", "Data and reprocess mode output file for rejected rows")
+	rejectsFormat := flag.String("rejects-format", "jsonl", "Data and reprocess mode rejected row format: jsonl or csv")
+	mappingFile := flag.String("mapping", "shared/mapping/es_index_v_0_1.json", "Data mode index settings and mappings file")
+	mappingVersion := flag.Int("mapping-version", 1, "Data mode mapping version, recorded in the versioned index name")
+	deleteOld := flag.Bool("delete-old", false, "Data mode deletes previous index versions after the alias is swapped")
+	reprocessIn := flag.String("reprocess-in", "data/rejected_rows.jsonl", "Reprocess mode JSONL rejected rows to repair")
+	zipCitiesFile := flag.String("zip-cities", "data/zip_cities.json", "ZIP code city table, written by data mode and read by api mode")
+	gridModel := flag.String("grid-model", "data/grid_model.json", "Grid model file, written by grid mode and read by api mode")
+	flag.Parse()
+
+	hosts := strings.Split(*esHosts, ",")
+	switch *mode {
+	case "api":
+		apiModule(hosts, *indexName, *listenAddr, *gridModel, *zipCitiesFile)
+	case "batch":
+		batchModule(hosts, *indexName, loadZipCities(*zipCitiesFile), *batchIn, *batchOut, api.BatchColumns{Address: *addressCol, City: *cityCol, Zip: *zipCol})
+	case "data":
+		config := data.ReindexConfig{Alias: *indexName, MappingFile: *mappingFile, Version: *mappingVersion, DeleteOld: *deleteOld}
+		dataModule(hosts, config, *sourceFile, *schemaFile, loadBoundary(*boundaryFile, *fixSwapped), loadAreaLayers(*areasFile), *zipCitiesFile, *rejectsFile, *rejectsFormat)
+	case "reprocess":
+		// Rows that still fail must not overwrite the file being reprocessed.
+		if *rejectsFile == *reprocessIn {
+			*rejectsFile = "data/still_rejected.jsonl"
+		}
+		reprocessModule(hosts, *indexName, *sourceFile, *schemaFile, loadBoundary(*boundaryFile, *fixSwapped), loadAreaLayers(*areasFile), *reprocessIn, *rejectsFile, *rejectsFormat)
+	case "grid":
+		gridModule(hosts, *indexName, *gridModel)
+	default:
+		log.Fatalf("Unknown mode %s", *mode)
+	}
+}
+
+func apiModule(hosts []string, indexName string, listenAddr string, gridModelFile string, zipCitiesFile string) {
+	client, err := data.BuildEsClient(hosts)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// The grid endpoint is optional. Run grid mode to build the model.
+	var gridModel *grid.Model
+	if model, err := grid.LoadModel(gridModelFile); err == nil {
+		gridModel = &model
+	} else if errors.Is(err, os.ErrNotExist) {
+		log.Printf("No grid model at %s, the grid endpoint is disabled\n", gridModelFile)
+	} else {
+		log.Fatal(err)
+	}
+	server := api.NewServer(api.NewSearcher(client, indexName, loadZipCities(zipCitiesFile)), gridModel)
+
+	log.Printf("API listening on %s\n", listenAddr)
+	log.Fatal(http.ListenAndServe(listenAddr, server))
 }
 
-func apiModule() {
+func batchModule(hosts []string, indexName string, zipCities zipcity.Table, inFile string, outFile string, columns api.BatchColumns) {
+	in, err := os.Open(inFile)
+	if err != nil {
+		log.Fatalf("Could not open batch input %s: %s", inFile, err)
+	}
+	defer in.Close()
+
+	out := os.Stdout
+	if outFile != "" {
+		out, err = os.Create(outFile)
+		if err != nil {
+			log.Fatalf("Could not create batch output %s: %s", outFile, err)
+		}
+		defer out.Close()
+	}
+
+	client, err := data.BuildEsClient(hosts)
+	if err != nil {
+		log.Fatal(err)
+	}
+	searcher := api.NewSearcher(client, indexName, zipCities)
+	stats, err := searcher.GeocodeCsv(context.Background(), in, out, columns)
+	if err != nil {
+		log.Fatalf("Batch stopped after %d rows: %s", stats.Rows, err)
+	}
+	log.Printf("Batch geocoded %d rows: %+v\n", stats.Rows, stats)
+}
 
+// gridModule fits the Chicago grid model to the indexed address points and saves it for the API.
+func gridModule(hosts []string, indexName string, gridModelFile string) {
+	client, err := data.BuildEsClient(hosts)
+	if err != nil {
+		log.Fatal(err)
+	}
+	model, err := data.BuildGridModel(client, indexName)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := model.Save(gridModelFile); err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("Saved grid model to %s: %+v\n", gridModelFile, model)
 }
 
-func dataModule() {
+// dataModule loads the source into a new versioned index and swaps the alias to it once the load is validated. The source
+// is read once beforehand to build the ZIP code city table, which fills in and checks cities during the load and is saved
+// for the API.
+func dataModule(hosts []string, config data.ReindexConfig, sourceFile string, schemaFile string, boundary *data.Boundary, layers data.AreaLayers, zipCitiesFile string, rejectsFile string, rejectsFormat string) {
+	schema, err := data.LoadSchema(schemaFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	client, err := data.BuildEsClient(hosts)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	zipCities, err := data.BuildZipCities(sourceReader(sourceFile, schema, nil, nil))
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := zipCities.Save(zipCitiesFile); err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("Saved ZIP code cities for %d ZIP codes to %s\n", len(zipCities), zipCitiesFile)
+
 	// TODO will need to read from s3
-	fileName := "data/Address_Points.csv"
-	normalizedChannel := make(chan data.Address)
-	errorChannel := make(chan string)
-	completeChannel := make(chan bool)
+	_, err = data.Reindex(client, config, func(indexName string) (data.BulkStats, error) {
+		return ingest(client, indexName, layers, rejectsFile, rejectsFormat, sourceReader(sourceFile, schema, boundary, zipCities))
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	logSwapped(boundary)
+}
 
-	go data.CsvReader(fileName, normalizedChannel, errorChannel, completeChannel)
+// sourceReader returns the reader for a source. An http or https source is read as an ArcGIS REST layer query and
+// anything else as a CSV file.
+func sourceReader(sourceFile string, schema data.Schema, boundary *data.Boundary, zipCities zipcity.Table) func(chan<- data.Address, chan<- data.RejectedRow) error {
+	return func(normalized chan<- data.Address, rejected chan<- data.RejectedRow) error {
+		if data.IsRemoteSource(sourceFile) {
+			return data.ArcGisReader(&http.Client{Timeout: sourceRequestTimeout}, sourceFile, schema, boundary, zipCities, normalized, rejected)
+		}
+		return data.CsvReader(sourceFile, schema, boundary, zipCities, normalized, rejected)
+	}
+}
+
+// reprocessModule repairs previously rejected rows and indexes the ones that can be recovered into the index behind the
+// alias. The source CSV is read first to learn its columns and the city and state of each ZIP code. Rows rejected from
+// an ArcGIS layer cannot be reprocessed, since the layer has no header row to map the rejected records with.
+func reprocessModule(hosts []string, indexName string, sourceFile string, schemaFile string, boundary *data.Boundary, layers data.AreaLayers, reprocessIn string, rejectsFile string, rejectsFormat string) {
+	schema, err := data.LoadSchema(schemaFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	columns, err := data.ReadColumns(sourceFile, schema)
+	if err != nil {
+		log.Fatal(err)
+	}
+	zipCities, err := data.BuildZipCities(sourceReader(sourceFile, schema, nil, nil))
+	if err != nil {
+		log.Fatal(err)
+	}
+	client, err := data.BuildEsClient(hosts)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	_, err = ingest(client, indexName, layers, rejectsFile, rejectsFormat, func(normalized chan<- data.Address, rejected chan<- data.RejectedRow) error {
+		previouslyRejected := make(chan data.RejectedRow, channelBuffer)
+		readErr := make(chan error, 1)
+		go func() { readErr <- data.RejectReader(reprocessIn, previouslyRejected) }()
+		data.Reprocess(previouslyRejected, columns, zipCities, boundary, normalized, rejected)
+		return <-readErr
+	})
+	var bulkErr *data.BulkIndexError
+	if errors.As(err, &bulkErr) {
+		log.Printf("Reprocess finished with failures: %s\n", bulkErr)
+	} else if err != nil {
+		log.Fatal(err)
+	}
+	logSwapped(boundary)
+}
+
+// loadZipCities reads the ZIP code city table for the API. The table is optional and written by data mode.
+func loadZipCities(zipCitiesFile string) zipcity.Table {
+	zipCities, err := zipcity.LoadTable(zipCitiesFile)
+	if errors.Is(err, os.ErrNotExist) {
+		log.Printf("No ZIP code cities at %s, cities are only matched by name\n", zipCitiesFile)
+		return nil
+	} else if err != nil {
+		log.Fatal(err)
+	}
+	return zipCities
+}
+
+// loadAreaLayers reads the area layers addresses are tagged with, or returns none when no layer list is given.
+func loadAreaLayers(areasFile string) data.AreaLayers {
+	if areasFile == "" {
+		return nil
+	}
+	layers, err := data.LoadAreaLayers(areasFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return layers
+}
+
+// loadBoundary reads the ingest boundary, or returns nil to skip the boundary check when no file is given.
+func loadBoundary(boundaryFile string, fixSwapped bool) *data.Boundary {
+	if boundaryFile == "" {
+		return nil
+	}
+	boundary, err := data.LoadBoundary(boundaryFile, fixSwapped)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return boundary
+}
+
+func logSwapped(boundary *data.Boundary) {
+	if boundary != nil && boundary.Swapped() > 0 {
+		log.Printf("Corrected swapped latitude and longitude in %d rows\n", boundary.Swapped())
+	}
+}
+
+// ingest runs a reader that produces addresses and rejected rows, tags the addresses with the areas containing them,
+// bulk indexes them and writes the rejected rows. The reader must close both channels when it returns. Reader and
+// reject writing errors take precedence over indexing errors.
+func ingest(client *elasticsearch.Client, indexName string, layers data.AreaLayers, rejectsFile string, rejectsFormat string, read func(chan<- data.Address, chan<- data.RejectedRow) error) (data.BulkStats, error) {
+	normalizedChannel := make(chan data.Address, channelBuffer)
+	rejectedChannel := make(chan data.RejectedRow, channelBuffer)
+	enrichedChannel := make(chan data.Address, channelBuffer)
+	esChannel := make(chan mapping.EsAddress, channelBuffer)
 
 	// TODO write to a configurable output. Local file or S3.
-	errors, err := os.OpenFile("data/normalize_errors.txt", os.O_TRUNC|os.O_CREATE|os.O_WRONLY, 0666)
-	if err != nil {
-		panic(err)
-	}
-
-	// Quick hack. TODO use a channel instead of building a huge slice.
-	bigSlice := make([]mapping.EsAddress, 2341113)
-
-	completeChannelOpen := true
-	for completeChannelOpen {
-		select {
-		case n := <-normalizedChannel:
-			esDoc := data.ToEsAddress(n)
-			bigSlice = append(bigSlice, esDoc)
-		case e := <-errorChannel:
-			str := fmt.Sprint(e)
-			if _, err := errors.WriteString(str + "\n"); err != nil {
-				panic(err)
-			}
-		case completeChannelOpen = <-completeChannel:
-		}
+	rejects, err := os.OpenFile(rejectsFile, os.O_TRUNC|os.O_CREATE|os.O_WRONLY, 0666)
+	if err != nil {
+		return data.BulkStats{}, err
+	}
+	defer rejects.Close()
+	rejectWriter, err := data.NewRejectWriter(rejectsFormat, rejects)
+	if err != nil {
+		return data.BulkStats{}, err
 	}
 
-	_ = errors.Close()
+	readErr := make(chan error, 1)
+	go func() { readErr <- read(normalizedChannel, rejectedChannel) }()
+	go data.EnrichAddresses(layers, normalizedChannel, enrichedChannel)
+	go data.ToEsAddresses(enrichedChannel, esChannel)
+
+	rejectsWritten := make(chan error, 1)
+	go func() {
+		counts, err := data.WriteRejects(rejectedChannel, rejectWriter)
+		log.Printf("Rejected rows by category: %v\n", counts)
+		rejectsWritten <- err
+	}()
+
+	stats, indexErr := data.BulkIndexEs(client, indexName, esChannel)
 
-	// TODO Requires index to be manually created, for now.
-	client := data.BuildEsClient([]string{"localhost:9200"})
-	data.BulkIndexEs(client, "address", bigSlice)
+	if err := <-rejectsWritten; err != nil {
+		return stats, err
+	}
+	if err := <-readErr; err != nil {
+		return stats, err
+	}
+	return stats, indexErr
 }
@@ -0,0 +1,43 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Intersection is a pair of cross streets, for example "State St & Madison St, Chicago". The city, state and ZIP code
+// follow the second street and are copied to both.
+type Intersection struct {
+	First  ParsedAddress
+	Second ParsedAddress
+}
+
+// intersectionPattern matches the connector between cross streets.
+var intersectionPattern = regexp.MustCompile(`\s*(?:&|@|/|\bAND\b)\s*`)
+
+// ParseIntersection splits the input on the first "&", "@", "/" or "AND" and parses each side as a street. ok is false
+// when the input is not an intersection, including when either side has a house number or unit, so the input can be
+// parsed as an address instead.
+func ParseIntersection(input string) (Intersection, bool) {
+	upper := strings.ToUpper(input)
+	loc := intersectionPattern.FindStringIndex(upper)
+	if loc == nil {
+		return Intersection{}, false
+	}
+
+	first, err := Parse(upper[:loc[0]])
+	if err != nil || !isStreetOnly(first) || first.Zip5 != "" {
+		return Intersection{}, false
+	}
+	second, err := Parse(upper[loc[1]:])
+	if err != nil || !isStreetOnly(second) {
+		return Intersection{}, false
+	}
+
+	first.City, first.State, first.Zip5, first.ZipLast4 = second.City, second.State, second.Zip5, second.ZipLast4
+	return Intersection{First: first, Second: second}, true
+}
+
+func isStreetOnly(parsed ParsedAddress) bool {
+	return parsed.Number == 0 && parsed.UnitDesignator == ""
+}
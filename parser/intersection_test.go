@@ -0,0 +1,55 @@
+package parser
+
+import "testing"
+
+func TestParseIntersection(t *testing.T) {
+	actual, ok := ParseIntersection("State St & Madison St, Chicago IL 60602")
+	if !ok {
+		t.Fatalf("Expected an intersection")
+	}
+	expected := Intersection{
+		First:  ParsedAddress{Street: "STATE", StreetSuffix: "ST", City: "CHICAGO", State: "IL", Zip5: "60602"},
+		Second: ParsedAddress{Street: "MADISON", StreetSuffix: "ST", City: "CHICAGO", State: "IL", Zip5: "60602"},
+	}
+	if actual != expected {
+		t.Errorf("Error parsing intersection. actual: %v expected: %v", actual, expected)
+	}
+}
+
+func TestParseIntersectionWithStreetNamedAfterDirection(t *testing.T) {
+	actual, ok := ParseIntersection("North Ave & Halsted St")
+	if !ok {
+		t.Fatalf("Expected an intersection")
+	}
+	if actual.First.StreetPrefix != "" || actual.First.Street != "NORTH" || actual.First.StreetSuffix != "AVE" || actual.Second.Street != "HALSTED" {
+		t.Errorf("Expected NORTH AVE to be the first street. actual: %v", actual)
+	}
+}
+
+func TestParseIntersectionConnectors(t *testing.T) {
+	for _, input := range []string{"N State and W Madison", "n state@w madison", "N State / W Madison"} {
+		actual, ok := ParseIntersection(input)
+		if !ok {
+			t.Errorf("Expected %q to be an intersection", input)
+			continue
+		}
+		if actual.First.StreetPrefix != "N" || actual.First.Street != "STATE" || actual.Second.StreetPrefix != "W" || actual.Second.Street != "MADISON" {
+			t.Errorf("Error parsing intersection %q. actual: %v", input, actual)
+		}
+	}
+}
+
+func TestParseIntersectionRejectsAddresses(t *testing.T) {
+	inputs := []string{
+		"1200 W Madison St",
+		"1234 1/2 S Halsted St",
+		"1234 & 1236 S Halsted St",
+		"100 Anderson St",
+		"State St &",
+	}
+	for _, input := range inputs {
+		if actual, ok := ParseIntersection(input); ok {
+			t.Errorf("Expected %q to not be an intersection. actual: %v", input, actual)
+		}
+	}
+}
@@ -0,0 +1,217 @@
+package parser
+
+import (
+	"cook-county-geocoder/shared/housenumber"
+	"cook-county-geocoder/shared/standardize"
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// ParsedAddress is a free text address split into its parts. The parts are named and standardized the same as indexed
+// addresses, so queries can be compared with indexed data field by field, including the secondary unit. HouseNumber is
+// the house number as written, such as 1234 1/2, and Number its numeric part.
+type ParsedAddress struct {
+	Number         int
+	HouseNumber    string
+	NumberFraction string
+	NumberSuffix   string
+	NumberLow      int
+	NumberHigh     int
+	StreetPrefix   string
+	Street         string
+	StreetSuffix   string
+	UnitDesignator string
+	UnitId         string
+	City           string
+	State          string
+	Zip5           string
+	ZipLast4       string
+}
+
+var (
+	ErrEmptyAddress = errors.New("address is empty")
+	ErrNoStreet     = errors.New("address does not contain a street name")
+
+	zipPattern = regexp.MustCompile(`^(\d{5})(?:-?(\d{4}))?$`)
+)
+
+// Parse splits a single line address such as "1200 W. Madison St Apt 3, Chicago IL 60607" into its parts. Commas are
+// used to separate the street from the city when present. Without commas the city is whatever follows the street
+// suffix or unit. Prefixes and suffixes are standardized to USPS abbreviations, the same as indexed data.
+func Parse(input string) (ParsedAddress, error) {
+	segments := tokenize(input)
+	if len(segments) == 0 {
+		return ParsedAddress{}, ErrEmptyAddress
+	}
+	parsed := ParsedAddress{}
+
+	// ZIP and state are read from the end of the last segment.
+	last := segments[len(segments)-1]
+	if n := len(last); n > 0 {
+		if match := zipPattern.FindStringSubmatch(last[n-1]); match != nil && (len(segments) > 1 || n > 1) {
+			parsed.Zip5, parsed.ZipLast4 = match[1], match[2]
+			last = last[:n-1]
+		}
+	}
+	if n := len(last); n > 0 && (len(segments) > 1 || n > 1) {
+		if state, ok := states[last[n-1]]; ok {
+			parsed.State = state
+			last = last[:n-1]
+		}
+	}
+	segments[len(segments)-1] = last
+
+	street := segments[0]
+	if len(segments) > 1 {
+		parsed.City = strings.Join(flatten(segments[1:]), " ")
+	}
+
+	if len(street) > 0 {
+		token := street[0]
+		// A fraction such as the 1/2 in "1234 1/2" is part of the house number.
+		if len(street) > 1 && housenumber.IsFraction(street[1]) {
+			token += " " + street[1]
+		}
+		if number, err := housenumber.Parse(token); err == nil {
+			setHouseNumber(&parsed, number)
+			street = street[len(strings.Fields(token)):]
+		}
+	}
+
+	if prefix, ok := directionalPrefix(street); ok {
+		parsed.StreetPrefix = prefix
+		street = street[1:]
+	}
+
+	var rest []string
+	street, parsed.UnitDesignator, parsed.UnitId, rest = splitUnit(street)
+
+	// Without a comma, a suffix marks the end of the street and the start of the city.
+	if len(segments) == 1 && rest == nil {
+		if end := streetEnd(street); end > 0 {
+			rest = street[end:]
+			street = street[:end]
+		}
+	}
+	if parsed.City == "" {
+		parsed.City = strings.Join(rest, " ")
+	}
+
+	if n := len(street); n > 1 {
+		if suffix, ok := standardize.Suffix(street[n-1]); ok {
+			parsed.StreetSuffix = suffix
+			street = street[:n-1]
+		}
+	}
+	parsed.Street = strings.Join(street, " ")
+	if parsed.Street == "" {
+		return parsed, ErrNoStreet
+	}
+	return parsed, nil
+}
+
+func setHouseNumber(parsed *ParsedAddress, number housenumber.HouseNumber) {
+	parsed.Number = number.Number
+	parsed.HouseNumber = number.Full
+	parsed.NumberFraction = number.Fraction
+	parsed.NumberSuffix = number.Suffix
+	parsed.NumberLow = number.Low
+	parsed.NumberHigh = number.High
+}
+
+// tokenize upper cases the input, drops periods and splits it into comma separated segments of space separated tokens.
+// "#" is always its own token so "#3" and "# 3" parse the same way.
+func tokenize(input string) [][]string {
+	replacer := strings.NewReplacer(".", "", "#", " # ")
+	segments := make([][]string, 0, 3)
+	for _, segment := range strings.Split(replacer.Replace(strings.ToUpper(input)), ",") {
+		if tokens := strings.Fields(segment); len(tokens) > 0 {
+			segments = append(segments, tokens)
+		}
+	}
+	return segments
+}
+
+// splitUnit finds a unit designator and its identifier. The designator is standardized to its USPS abbreviation. Tokens
+// before the designator are the street and anything after the identifier is returned as rest. rest is nil if no unit was
+// found.
+func splitUnit(tokens []string) (street []string, designator string, id string, rest []string) {
+	for i := 1; i < len(tokens); i++ {
+		if !unitDesignators[tokens[i]] {
+			continue
+		}
+		designator, _ = standardize.UnitDesignator(tokens[i])
+		end := i + 1
+		if end < len(tokens) {
+			id = tokens[end]
+			end++
+		}
+		return tokens[:i], designator, id, tokens[end:]
+	}
+	return tokens, "", "", nil
+}
+
+// directionalPrefix returns the standardized directional at the start of the street tokens when it is a prefix rather
+// than the street name. It is a prefix only when a street name follows it: a token that is not a suffix, or a suffix
+// such as PARK followed by the suffix ending the street. So N Halsted St and N Park Ave have a prefix, while North Ave
+// and South Blvd Evanston are streets named after a direction.
+func directionalPrefix(tokens []string) (string, bool) {
+	if len(tokens) < 2 {
+		return "", false
+	}
+	prefix, ok := standardize.Directional(tokens[0])
+	if !ok {
+		return "", false
+	}
+	name, _, _, _ := splitUnit(tokens[1:])
+	if _, isSuffix := standardize.Suffix(name[0]); isSuffix && streetEnd(name) == 0 {
+		return "", false
+	}
+	return prefix, true
+}
+
+// streetEnd returns the index just past the suffix that ends the street, or 0 if there is no suffix. Many suffixes are
+// also common in city names (OAK PARK, RIVER FOREST, ARLINGTON HEIGHTS), so the first of the everyday street suffixes
+// is preferred over the last suffix of any kind.
+func streetEnd(tokens []string) int {
+	last := 0
+	for i := 1; i < len(tokens); i++ {
+		suffix, ok := standardize.Suffix(tokens[i])
+		if !ok {
+			continue
+		}
+		if commonSuffixes[suffix] {
+			return i + 1
+		}
+		last = i + 1
+	}
+	return last
+}
+
+func flatten(segments [][]string) []string {
+	tokens := make([]string, 0)
+	for _, segment := range segments {
+		tokens = append(tokens, segment...)
+	}
+	return tokens
+}
+
+// unitDesignators are the spellings of the designators that take an identifier. Designators that stand alone, such as
+// REAR or LOWER, are left out because they are also words in street names like LOWER WACKER.
+var unitDesignators = map[string]bool{
+	"#": true, "APT": true, "APARTMENT": true, "UNIT": true, "STE": true, "SUITE": true, "FL": true, "FLOOR": true,
+	"RM": true, "ROOM": true, "BLDG": true, "BUILDING": true, "DEPT": true, "LOT": true, "SPC": true, "SPACE": true,
+}
+
+// commonSuffixes are the standardized suffixes that rarely appear in Cook County city names.
+var commonSuffixes = map[string]bool{
+	"AVE": true, "ST": true, "RD": true, "BLVD": true, "DR": true, "LN": true, "CT": true, "PL": true, "PKWY": true,
+	"TER": true, "CIR": true, "HWY": true, "WAY": true, "TRL": true, "SQ": true, "EXPY": true,
+}
+
+// states maps accepted state spellings to their USPS abbreviation.
+var states = map[string]string{
+	"IL": "IL", "ILLINOIS": "IL", "IN": "IN", "INDIANA": "IN", "WI": "WI", "WISCONSIN": "WI",
+	"MI": "MI", "MICHIGAN": "MI", "IA": "IA", "IOWA": "IA", "MO": "MO", "MISSOURI": "MO",
+}
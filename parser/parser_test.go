@@ -0,0 +1,169 @@
+package parser
+
+import "testing"
+
+func TestParseFullAddressWithCommas(t *testing.T) {
+	actual, err := Parse("1200 W. Madison St Apt 3, Chicago IL 60607-1234")
+	if err != nil {
+		t.Fatalf("Expected no errors with a full address. Found %v", err)
+	}
+	expected := ParsedAddress{
+		Number:         1200,
+		HouseNumber:    "1200",
+		StreetPrefix:   "W",
+		Street:         "MADISON",
+		StreetSuffix:   "ST",
+		City:           "CHICAGO",
+		State:          "IL",
+		Zip5:           "60607",
+		ZipLast4:       "1234",
+		UnitDesignator: "APT",
+		UnitId:         "3",
+	}
+	if actual != expected {
+		t.Errorf("Error parsing address. actual: %v expected: %v", actual, expected)
+	}
+}
+
+func TestParseAddressWithoutCommas(t *testing.T) {
+	actual, err := Parse("5400 n lincoln ave chicago heights illinois 60659")
+	if err != nil {
+		t.Fatalf("Expected no errors. Found %v", err)
+	}
+	expected := ParsedAddress{
+		Number:       5400,
+		HouseNumber:  "5400",
+		StreetPrefix: "N",
+		Street:       "LINCOLN",
+		StreetSuffix: "AVE",
+		City:         "CHICAGO HEIGHTS",
+		State:        "IL",
+		Zip5:         "60659",
+	}
+	if actual != expected {
+		t.Errorf("Error parsing address. actual: %v expected: %v", actual, expected)
+	}
+}
+
+func TestParseUnitWithPoundSign(t *testing.T) {
+	actual, err := Parse("33 N LaSalle St #2100 Chicago")
+	if err != nil {
+		t.Fatalf("Expected no errors. Found %v", err)
+	}
+	if actual.UnitDesignator != "#" || actual.UnitId != "2100" || actual.Street != "LASALLE" || actual.City != "CHICAGO" {
+		t.Errorf("Error parsing unit. actual: %v", actual)
+	}
+}
+
+func TestParseStandardizesUnitDesignator(t *testing.T) {
+	actual, err := Parse("200 E Randolph St Suite 5100, Chicago")
+	if err != nil {
+		t.Fatalf("Expected no errors. Found %v", err)
+	}
+	if actual.UnitDesignator != "STE" || actual.UnitId != "5100" || actual.Street != "RANDOLPH" {
+		t.Errorf("Error parsing unit. actual: %v", actual)
+	}
+}
+
+func TestParseStreetOnly(t *testing.T) {
+	actual, err := Parse("Madison")
+	if err != nil {
+		t.Fatalf("Expected no errors. Found %v", err)
+	}
+	expected := ParsedAddress{Street: "MADISON"}
+	if actual != expected {
+		t.Errorf("Error parsing street only. actual: %v expected: %v", actual, expected)
+	}
+}
+
+func TestParseDoesNotTreatStreetNamesAsPrefixOrSuffix(t *testing.T) {
+	// A single token after the number is always the street, even when it is also a directional or suffix.
+	actual, err := Parse("100 West")
+	if err != nil {
+		t.Fatalf("Expected no errors. Found %v", err)
+	}
+	if actual.Street != "WEST" || actual.StreetPrefix != "" {
+		t.Errorf("Expected WEST to be the street name. actual: %v", actual)
+	}
+
+	actual, err = Parse("100 Court")
+	if err != nil {
+		t.Fatalf("Expected no errors. Found %v", err)
+	}
+	if actual.Street != "COURT" || actual.StreetSuffix != "" {
+		t.Errorf("Expected COURT to be the street name. actual: %v", actual)
+	}
+}
+
+func TestParseStreetsNamedAfterDirections(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected ParsedAddress
+	}{
+		{"1600 North Ave, Melrose Park", ParsedAddress{Number: 1600, HouseNumber: "1600", Street: "NORTH", StreetSuffix: "AVE", City: "MELROSE PARK"}},
+		{"100 South Blvd Evanston", ParsedAddress{Number: 100, HouseNumber: "100", Street: "SOUTH", StreetSuffix: "BLVD", City: "EVANSTON"}},
+		{"1000 East Ave, Berwyn", ParsedAddress{Number: 1000, HouseNumber: "1000", Street: "EAST", StreetSuffix: "AVE", City: "BERWYN"}},
+		{"1600 W North Ave Apt 2", ParsedAddress{Number: 1600, HouseNumber: "1600", StreetPrefix: "W", Street: "NORTH", StreetSuffix: "AVE", UnitDesignator: "APT", UnitId: "2"}},
+		{"100 N Park Ave", ParsedAddress{Number: 100, HouseNumber: "100", StreetPrefix: "N", Street: "PARK", StreetSuffix: "AVE"}},
+	}
+	for _, c := range cases {
+		actual, err := Parse(c.input)
+		if err != nil || actual != c.expected {
+			t.Errorf("Error parsing %q. actual: %v err: %v expected: %v", c.input, actual, err, c.expected)
+		}
+	}
+}
+
+func TestParseKeepsNumberFractionLetterAndRange(t *testing.T) {
+	actual, err := Parse("1234 1/2 S Halsted St")
+	if err != nil || actual.Number != 1234 || actual.HouseNumber != "1234 1/2" || actual.NumberFraction != "1/2" || actual.Street != "HALSTED" {
+		t.Errorf("Error parsing fractional number. actual: %v err: %v", actual, err)
+	}
+
+	actual, err = Parse("1234A S Halsted St")
+	if err != nil || actual.Number != 1234 || actual.HouseNumber != "1234A" || actual.NumberSuffix != "A" || actual.Street != "HALSTED" {
+		t.Errorf("Error parsing number with letter. actual: %v err: %v", actual, err)
+	}
+
+	actual, err = Parse("12-14 S Halsted St")
+	if err != nil || actual.Number != 12 || actual.NumberHigh != 14 || actual.Street != "HALSTED" {
+		t.Errorf("Error parsing number range. actual: %v err: %v", actual, err)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	if _, err := Parse("  , "); err != ErrEmptyAddress {
+		t.Errorf("Expected ErrEmptyAddress. actual: %v", err)
+	}
+	if _, err := Parse("1200, Chicago IL 60607"); err != ErrNoStreet {
+		t.Errorf("Expected ErrNoStreet. actual: %v", err)
+	}
+}
+
+func TestParseStandardizesPrefixAndSuffix(t *testing.T) {
+	actual, err := Parse("100 North Lake Shore Drive, Chicago")
+	if err != nil {
+		t.Fatalf("Expected no errors. Found %v", err)
+	}
+	if actual.StreetPrefix != "N" || actual.Street != "LAKE SHORE" || actual.StreetSuffix != "DR" {
+		t.Errorf("Expected standardized prefix and suffix. actual: %v", actual)
+	}
+}
+
+func TestParseCityEndingInSuffixWithoutCommas(t *testing.T) {
+	actual, err := Parse("1000 Park Avenue River Forest IL")
+	if err != nil {
+		t.Fatalf("Expected no errors. Found %v", err)
+	}
+	if actual.Street != "PARK" || actual.StreetSuffix != "AVE" || actual.City != "RIVER FOREST" {
+		t.Errorf("Expected RIVER FOREST to be the city. actual: %v", actual)
+	}
+
+	actual, err = Parse("100 N Lake Shore Dr Chicago")
+	if err != nil {
+		t.Fatalf("Expected no errors. Found %v", err)
+	}
+	if actual.Street != "LAKE SHORE" || actual.StreetSuffix != "DR" || actual.City != "CHICAGO" {
+		t.Errorf("Expected LAKE SHORE to be the street. actual: %v", actual)
+	}
+}
@@ -0,0 +1,41 @@
+package parser
+
+import "strings"
+
+// Place is a query naming only a city, a ZIP code or both, for example "60607" or "Oak Park, IL".
+type Place struct {
+	City  string
+	State string
+	Zip5  string
+}
+
+// ParsePlace reads an input with no house number, street number or unit: an optional city followed by an optional state
+// and ZIP code. ok is false when the input has any other number, a unit, or neither a city nor a ZIP code. A single word
+// such as "Madison" may be a street rather than a city, so callers decide whether the city is one they know.
+func ParsePlace(input string) (Place, bool) {
+	tokens := flatten(tokenize(input))
+	place := Place{}
+	if n := len(tokens); n > 0 {
+		if match := zipPattern.FindStringSubmatch(tokens[n-1]); match != nil {
+			place.Zip5 = match[1]
+			tokens = tokens[:n-1]
+		}
+	}
+	// A lone state, like IN or OR, is more likely a word than a place.
+	if n := len(tokens); n > 0 && (n > 1 || place.Zip5 != "") {
+		if state, ok := states[tokens[n-1]]; ok {
+			place.State = state
+			tokens = tokens[:n-1]
+		}
+	}
+	for _, token := range tokens {
+		if unitDesignators[token] || strings.ContainsAny(token, "0123456789") {
+			return Place{}, false
+		}
+	}
+	place.City = strings.Join(tokens, " ")
+	if place.City == "" && place.Zip5 == "" {
+		return Place{}, false
+	}
+	return place, true
+}
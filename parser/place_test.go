@@ -0,0 +1,31 @@
+package parser
+
+import "testing"
+
+func TestParsePlace(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected Place
+	}{
+		{"60607", Place{Zip5: "60607"}},
+		{"60607-1234", Place{Zip5: "60607"}},
+		{"IL 60607", Place{State: "IL", Zip5: "60607"}},
+		{"Chicago", Place{City: "CHICAGO"}},
+		{"Oak Park, IL", Place{City: "OAK PARK", State: "IL"}},
+		{"Oak Park, Illinois 60302", Place{City: "OAK PARK", State: "IL", Zip5: "60302"}},
+	}
+	for _, c := range cases {
+		actual, ok := ParsePlace(c.input)
+		if !ok || actual != c.expected {
+			t.Errorf("Error parsing place %q. actual: %v %v expected: %v", c.input, actual, ok, c.expected)
+		}
+	}
+}
+
+func TestParsePlaceRejectsAddresses(t *testing.T) {
+	for _, input := range []string{"", "...", "1234", "1200 W Madison St 60607", "95th St, Oak Lawn", "Apt 3, Chicago"} {
+		if actual, ok := ParsePlace(input); ok {
+			t.Errorf("Expected %q not to be a place. actual: %v", input, actual)
+		}
+	}
+}
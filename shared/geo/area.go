@@ -0,0 +1,149 @@
+package geo
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+)
+
+// Polygon is a GeoJSON polygon: an outer ring followed by any holes, each a closed list of [longitude, latitude]
+// positions.
+type Polygon [][][2]float64
+
+// Contains reports whether the point is inside the polygon and outside its holes, by the even-odd rule. Points exactly
+// on an edge may fall either way.
+func (p Polygon) Contains(lat float64, lon float64) bool {
+	inside := false
+	for _, ring := range p {
+		for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+			lon1, lat1 := ring[i][0], ring[i][1]
+			lon2, lat2 := ring[j][0], ring[j][1]
+			if (lat1 > lat) != (lat2 > lat) && lon < (lon2-lon1)*(lat-lat1)/(lat2-lat1)+lon1 {
+				inside = !inside
+			}
+		}
+	}
+	return inside
+}
+
+// Area is a region made of one or more polygons, such as a county or a ward, with the properties of the GeoJSON feature
+// it was read from.
+type Area struct {
+	Properties map[string]interface{}
+	Polygons   []Polygon
+	// The bounding box skips the polygon test for points clearly outside the area.
+	minLat, minLon, maxLat, maxLon float64
+}
+
+// NewArea returns an Area of the polygons.
+func NewArea(properties map[string]interface{}, polygons ...Polygon) Area {
+	area := Area{Properties: properties, Polygons: polygons, minLat: math.Inf(1), minLon: math.Inf(1), maxLat: math.Inf(-1), maxLon: math.Inf(-1)}
+	for _, polygon := range polygons {
+		for _, ring := range polygon {
+			for _, position := range ring {
+				area.minLon, area.maxLon = math.Min(area.minLon, position[0]), math.Max(area.maxLon, position[0])
+				area.minLat, area.maxLat = math.Min(area.minLat, position[1]), math.Max(area.maxLat, position[1])
+			}
+		}
+	}
+	return area
+}
+
+// Contains reports whether the point is inside any of the area's polygons.
+func (a Area) Contains(lat float64, lon float64) bool {
+	if lat < a.minLat || lat > a.maxLat || lon < a.minLon || lon > a.maxLon {
+		return false
+	}
+	for _, polygon := range a.Polygons {
+		if polygon.Contains(lat, lon) {
+			return true
+		}
+	}
+	return false
+}
+
+// empty reports whether the area has no positions, so no point can be inside it.
+func (a Area) empty() bool {
+	return a.minLat > a.maxLat
+}
+
+// Property returns a feature property as a string, or an empty string when the feature does not have it. Numbers are
+// written without an exponent, so numeric IDs such as census tract GEOIDs keep every digit.
+func (a Area) Property(name string) string {
+	switch value := a.Properties[name].(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+// geoJson is any GeoJSON object. Only the members used for polygons are mapped.
+type geoJson struct {
+	Type        string                 `json:"type"`
+	Features    []geoJson              `json:"features"`
+	Geometry    *geoJson               `json:"geometry"`
+	Properties  map[string]interface{} `json:"properties"`
+	Coordinates json.RawMessage        `json:"coordinates"`
+}
+
+// LoadAreas reads the areas from a GeoJSON file holding a FeatureCollection, a Feature or a bare Polygon or
+// MultiPolygon geometry. Each feature is one area. Other geometry types are an error.
+func LoadAreas(path string) ([]Area, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var object geoJson
+	if err := json.Unmarshal(raw, &object); err != nil {
+		return nil, fmt.Errorf("could not decode GeoJSON %s: %w", path, err)
+	}
+
+	var features []geoJson
+	switch object.Type {
+	case "FeatureCollection":
+		features = object.Features
+	case "Feature":
+		features = []geoJson{object}
+	default:
+		features = []geoJson{{Type: "Feature", Geometry: &object}}
+	}
+
+	areas := make([]Area, 0, len(features))
+	for i, feature := range features {
+		if feature.Geometry == nil {
+			return nil, fmt.Errorf("feature %d in %s has no geometry", i, path)
+		}
+		polygons, err := feature.Geometry.polygons()
+		if err != nil {
+			return nil, fmt.Errorf("feature %d in %s: %w", i, path, err)
+		}
+		areas = append(areas, NewArea(feature.Properties, polygons...))
+	}
+	return areas, nil
+}
+
+func (g geoJson) polygons() ([]Polygon, error) {
+	switch g.Type {
+	case "Polygon":
+		var polygon Polygon
+		if err := json.Unmarshal(g.Coordinates, &polygon); err != nil {
+			return nil, fmt.Errorf("could not decode polygon coordinates: %w", err)
+		}
+		return []Polygon{polygon}, nil
+	case "MultiPolygon":
+		var polygons []Polygon
+		if err := json.Unmarshal(g.Coordinates, &polygons); err != nil {
+			return nil, fmt.Errorf("could not decode multipolygon coordinates: %w", err)
+		}
+		return polygons, nil
+	default:
+		return nil, fmt.Errorf("unsupported geometry type %q", g.Type)
+	}
+}
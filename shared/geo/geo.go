@@ -0,0 +1,25 @@
+package geo
+
+import "math"
+
+// EarthRadiusMeters is the mean earth radius, the same value Elasticsearch uses for arc distances.
+const EarthRadiusMeters = 6371008.7714
+
+// Distance returns the great circle distance in meters between two points given in degrees, using the haversine
+// formula.
+func Distance(lat1 float64, lon1 float64, lat2 float64, lon2 float64) float64 {
+	phi1, phi2 := radians(lat1), radians(lat2)
+	dPhi, dLambda := radians(lat2-lat1), radians(lon2-lon1)
+	a := math.Sin(dPhi/2)*math.Sin(dPhi/2) + math.Cos(phi1)*math.Cos(phi2)*math.Sin(dLambda/2)*math.Sin(dLambda/2)
+	return 2 * EarthRadiusMeters * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// Midpoint returns the point halfway between two nearby points. It averages the coordinates, which is accurate for
+// points a few kilometers apart but not across long distances.
+func Midpoint(lat1 float64, lon1 float64, lat2 float64, lon2 float64) (float64, float64) {
+	return (lat1 + lat2) / 2, (lon1 + lon2) / 2
+}
+
+func radians(degrees float64) float64 {
+	return degrees * math.Pi / 180
+}
@@ -0,0 +1,130 @@
+package geo
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDistance(t *testing.T) {
+	// State and Madison to Daley Plaza, about 280 meters.
+	actual := Distance(41.88199, -87.62783, 41.88375, -87.63028)
+	if math.Abs(actual-280) > 10 {
+		t.Errorf("Unexpected distance. actual: %f", actual)
+	}
+	if actual := Distance(41.88, -87.62, 41.88, -87.62); actual != 0 {
+		t.Errorf("Expected no distance between the same point. actual: %f", actual)
+	}
+	// One degree of latitude.
+	if actual := Distance(41, -87, 42, -87); math.Abs(actual-111195) > 1 {
+		t.Errorf("Unexpected distance for one degree of latitude. actual: %f", actual)
+	}
+}
+
+func TestMidpoint(t *testing.T) {
+	lat, lon := Midpoint(41.88, -87.63, 41.89, -87.62)
+	if math.Abs(lat-41.885) > 1e-9 || math.Abs(lon+87.625) > 1e-9 {
+		t.Errorf("Unexpected midpoint. actual: %f,%f", lat, lon)
+	}
+}
+
+func TestLoadAreas(t *testing.T) {
+	areas, err := LoadAreas("testdata/areas.geojson")
+	if err != nil {
+		t.Fatalf("Expected no errors loading areas. Found %v", err)
+	}
+	if len(areas) != 2 || areas[0].Property("name") != "Square With Hole" || areas[0].Property("id") != "1" || areas[1].Property("missing") != "" || areas[1].Property("GEOID") != "17031839100" {
+		t.Fatalf("Unexpected areas. actual: %v", areas)
+	}
+
+	cases := []struct {
+		area     int
+		lat, lon float64
+		expected bool
+	}{
+		{0, 41.2, -87.8, true},
+		// Inside the hole.
+		{0, 41.5, -87.5, false},
+		{0, 42.5, -87.5, false},
+		{1, 40.2, -89.2, true},
+		// Below the diagonal edge of the triangle, inside its bounding box.
+		{1, 40.8, -89.8, false},
+		{1, 43.2, -85.2, true},
+		{1, 42.0, -87.5, false},
+	}
+	for _, c := range cases {
+		if actual := areas[c.area].Contains(c.lat, c.lon); actual != c.expected {
+			t.Errorf("Unexpected containment of %f,%f in %s. actual: %v", c.lat, c.lon, areas[c.area].Property("name"), actual)
+		}
+	}
+}
+
+func TestLoadAreasRejectsOtherGeometries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "point.geojson")
+	if err := os.WriteFile(path, []byte(`{"type": "Point", "coordinates": [-87.6, 41.8]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadAreas(path); err == nil {
+		t.Errorf("Expected an error for a point geometry.")
+	}
+	if _, err := LoadAreas("testdata/missing.geojson"); !os.IsNotExist(err) {
+		t.Errorf("Expected a not exist error for a missing file. actual: %v", err)
+	}
+}
+
+func TestAreaIndexFindsFirstContainingArea(t *testing.T) {
+	// A 100 by 100 grid of squares 0.001 degrees wide, then one large square over all of them.
+	var areas []Area
+	for row := 0; row < 100; row++ {
+		for col := 0; col < 100; col++ {
+			lat, lon := 41.8+float64(row)*0.001, -87.7+float64(col)*0.001
+			areas = append(areas, NewArea(map[string]interface{}{"id": float64(row*100 + col)}, square(lat, lon, 0.001)))
+		}
+	}
+	areas = append(areas, NewArea(map[string]interface{}{"id": "large"}, square(41.7, -87.8, 0.3)))
+	index := NewAreaIndex(areas)
+	if index.Len() != 10001 {
+		t.Errorf("Expected every area to be indexed. actual: %d", index.Len())
+	}
+
+	cases := []struct {
+		lat, lon float64
+		expected string
+	}{
+		{41.8005, -87.6995, "0"},
+		{41.8425, -87.6575, "4242"},
+		{41.8995, -87.6005, "9999"},
+		// Outside the small squares but inside the large one.
+		{41.75, -87.75, "large"},
+		{41.95, -87.65, "large"},
+	}
+	for _, c := range cases {
+		area, ok := index.Find(c.lat, c.lon)
+		if !ok || area.Property("id") != c.expected {
+			t.Errorf("Unexpected area for %f,%f. actual: %v %v expected: %s", c.lat, c.lon, area.Properties, ok, c.expected)
+		}
+	}
+	for _, point := range [][2]float64{{41.6, -87.65}, {41.85, -87.4}, {42.1, -87.65}, {41.85, -88}, {math.NaN(), math.NaN()}} {
+		if area, ok := index.Find(point[0], point[1]); ok {
+			t.Errorf("Expected no area for %f,%f. actual: %v", point[0], point[1], area.Properties)
+		}
+	}
+}
+
+func TestAreaIndexWithoutAreas(t *testing.T) {
+	for _, index := range []*AreaIndex{NewAreaIndex(nil), NewAreaIndex([]Area{NewArea(nil)})} {
+		if _, ok := index.Find(41.85, -87.65); ok {
+			t.Errorf("Expected no area in an empty index.")
+		}
+	}
+	// A single point sized area.
+	index := NewAreaIndex([]Area{NewArea(nil, Polygon{{{-87.65, 41.85}, {-87.65, 41.85}}})})
+	if _, ok := index.Find(41.85, -87.65); ok {
+		t.Errorf("Expected no area inside a point.")
+	}
+}
+
+func square(lat float64, lon float64, size float64) Polygon {
+	return Polygon{{{lon, lat}, {lon + size, lat}, {lon + size, lat + size}, {lon, lat + size}, {lon, lat}}}
+}
@@ -0,0 +1,87 @@
+package geo
+
+import "math"
+
+// AreaIndex finds the areas containing a point without testing every area. The bounding box of each area is bucketed
+// into a uniform latitude and longitude grid, and a lookup only tests the areas whose boxes overlap the point's cell.
+type AreaIndex struct {
+	areas          []Area
+	minLat, minLon float64
+	cellSize       float64
+	rows, cols     int
+	// cells holds, for each cell in row major order, the positions of the areas overlapping it in ascending order.
+	cells [][]int32
+}
+
+// maxCellsPerArea bounds the cells along the longer side of a long, thin extent, where one cell per area would make
+// cells too small to hold an area.
+const maxCellsPerArea = 4
+
+// NewAreaIndex indexes the areas. The cell size is chosen so the grid has about one cell per area over the extent of all
+// the areas, which keeps the candidates for a point to a handful whether the layer holds wards or parcels.
+func NewAreaIndex(areas []Area) *AreaIndex {
+	index := &AreaIndex{areas: areas, minLat: math.Inf(1), minLon: math.Inf(1)}
+	maxLat, maxLon := math.Inf(-1), math.Inf(-1)
+	for _, area := range areas {
+		if area.empty() {
+			continue
+		}
+		index.minLat, index.minLon = math.Min(index.minLat, area.minLat), math.Min(index.minLon, area.minLon)
+		maxLat, maxLon = math.Max(maxLat, area.maxLat), math.Max(maxLon, area.maxLon)
+	}
+	if math.IsInf(index.minLat, 1) {
+		return index
+	}
+
+	height, width := maxLat-index.minLat, maxLon-index.minLon
+	index.cellSize = math.Sqrt(height * width / float64(len(areas)))
+	// Long, thin or point sized extents fall back to cells sized by the longer side.
+	index.cellSize = math.Max(index.cellSize, math.Max(height, width)/float64(maxCellsPerArea*len(areas)))
+	if index.cellSize == 0 {
+		index.cellSize = 1
+	}
+	index.rows = int(height/index.cellSize) + 1
+	index.cols = int(width/index.cellSize) + 1
+	index.cells = make([][]int32, index.rows*index.cols)
+
+	for i, area := range areas {
+		if area.empty() {
+			continue
+		}
+		row1, col1 := index.cell(area.minLat, area.minLon)
+		row2, col2 := index.cell(area.maxLat, area.maxLon)
+		for row := row1; row <= row2; row++ {
+			for col := col1; col <= col2; col++ {
+				index.cells[row*index.cols+col] = append(index.cells[row*index.cols+col], int32(i))
+			}
+		}
+	}
+	return index
+}
+
+// Len returns the number of indexed areas.
+func (x *AreaIndex) Len() int {
+	return len(x.areas)
+}
+
+// Find returns the first area, in the order given to NewAreaIndex, containing the point.
+func (x *AreaIndex) Find(lat float64, lon float64) (Area, bool) {
+	if x.rows == 0 || !(lat >= x.minLat && lon >= x.minLon) {
+		return Area{}, false
+	}
+	row, col := x.cell(lat, lon)
+	if row >= x.rows || col >= x.cols {
+		return Area{}, false
+	}
+	for _, i := range x.cells[row*x.cols+col] {
+		if x.areas[i].Contains(lat, lon) {
+			return x.areas[i], true
+		}
+	}
+	return Area{}, false
+}
+
+// cell returns the row and column of the grid cell holding the point. The point must not be below or left of the grid.
+func (x *AreaIndex) cell(lat float64, lon float64) (int, int) {
+	return int((lat - x.minLat) / x.cellSize), int((lon - x.minLon) / x.cellSize)
+}
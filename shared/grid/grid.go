@@ -0,0 +1,225 @@
+package grid
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// The Chicago address grid. House numbers on streets with a N or S prefix count blocks north or south of Madison St,
+// and numbers on streets with an E or W prefix count east or west of State St, so "800 N 1200 W" is a location 800
+// north and 1200 west of State and Madison. The grid is close to aligned with latitude and longitude, so each axis is
+// modeled as a straight line fitted to the address points.
+
+var (
+	ErrTooFewSamples = errors.New("too few address points to fit the grid")
+	ErrBadCoordinate = errors.New("grid coordinate must have a N or S part and an E or W part, for example 800 N 1200 W")
+)
+
+// MinSamples is the fewest address points on each axis needed to fit a model.
+const MinSamples = 100
+
+// Axis maps a signed grid coordinate, north or east positive, to latitude or longitude as Intercept + Slope * coordinate.
+// RMSE is the root mean squared error of the fit in degrees.
+type Axis struct {
+	Intercept float64 `json:"intercept"`
+	Slope     float64 `json:"slope"`
+	Samples   int     `json:"samples"`
+	RMSE      float64 `json:"rmse"`
+}
+
+// Model converts between grid coordinates and latitude and longitude.
+type Model struct {
+	NorthSouth Axis `json:"north_south"`
+	EastWest   Axis `json:"east_west"`
+}
+
+// Locate returns the latitude and longitude of a signed grid coordinate.
+func (m Model) Locate(northSouth float64, eastWest float64) (float64, float64) {
+	return m.NorthSouth.Intercept + m.NorthSouth.Slope*northSouth, m.EastWest.Intercept + m.EastWest.Slope*eastWest
+}
+
+// Coordinate returns the signed grid coordinate of a location, rounded to the nearest address number.
+func (m Model) Coordinate(lat float64, lon float64) (int, int) {
+	northSouth := (lat - m.NorthSouth.Intercept) / m.NorthSouth.Slope
+	eastWest := (lon - m.EastWest.Intercept) / m.EastWest.Slope
+	return int(math.Round(northSouth)), int(math.Round(eastWest))
+}
+
+// Save writes the model as JSON.
+func (m Model) Save(path string) error {
+	encoded, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, encoded, 0666)
+}
+
+// LoadModel reads a model written by Save.
+func LoadModel(path string) (Model, error) {
+	file, err := os.ReadFile(path)
+	if err != nil {
+		return Model{}, err
+	}
+	var model Model
+	if err := json.Unmarshal(file, &model); err != nil {
+		return Model{}, fmt.Errorf("could not decode grid model %s: %w", path, err)
+	}
+	if model.NorthSouth.Slope == 0 || model.EastWest.Slope == 0 {
+		return Model{}, fmt.Errorf("grid model %s has a zero slope", path)
+	}
+	return model, nil
+}
+
+// Format writes a signed grid coordinate the way it is spoken, for example "800 N 1200 W".
+func Format(northSouth int, eastWest int) string {
+	ns, ew := "N", "E"
+	if northSouth < 0 {
+		ns, northSouth = "S", -northSouth
+	}
+	if eastWest < 0 {
+		ew, eastWest = "W", -eastWest
+	}
+	return fmt.Sprintf("%d %s %d %s", northSouth, ns, eastWest, ew)
+}
+
+var coordinatePartPattern = regexp.MustCompile(`(?i)(?:(\d+)\s*([NSEW])\b|\b([NSEW])\s*(\d+))`)
+
+// Parse reads a grid coordinate such as "800 N 1200 W", "N800 W1200" or "1200 W, 800 N" into signed north and east
+// parts.
+func Parse(input string) (int, int, error) {
+	matches := coordinatePartPattern.FindAllStringSubmatch(input, -1)
+	rest := strings.TrimSpace(strings.Trim(coordinatePartPattern.ReplaceAllString(input, ""), " ,&"))
+	if len(matches) != 2 || rest != "" {
+		return 0, 0, ErrBadCoordinate
+	}
+
+	var northSouth, eastWest *int
+	for _, match := range matches {
+		number, direction := match[1], match[2]
+		if number == "" {
+			number, direction = match[4], match[3]
+		}
+		value, err := strconv.Atoi(number)
+		if err != nil {
+			return 0, 0, ErrBadCoordinate
+		}
+		switch strings.ToUpper(direction) {
+		case "S":
+			value = -value
+			fallthrough
+		case "N":
+			if northSouth != nil {
+				return 0, 0, ErrBadCoordinate
+			}
+			northSouth = &value
+		case "W":
+			value = -value
+			fallthrough
+		case "E":
+			if eastWest != nil {
+				return 0, 0, ErrBadCoordinate
+			}
+			eastWest = &value
+		}
+	}
+	if northSouth == nil || eastWest == nil {
+		return 0, 0, ErrBadCoordinate
+	}
+	return *northSouth, *eastWest, nil
+}
+
+// Builder collects address points and fits a Model to them.
+type Builder struct {
+	northSouth []sample
+	eastWest   []sample
+}
+
+type sample struct {
+	coordinate float64
+	degrees    float64
+}
+
+// Add records an address point. Points without a N, S, E or W prefix say nothing about the grid and are ignored.
+func (b *Builder) Add(number int, prefix string, lat float64, lon float64) {
+	switch prefix {
+	case "N":
+		b.northSouth = append(b.northSouth, sample{coordinate: float64(number), degrees: lat})
+	case "S":
+		b.northSouth = append(b.northSouth, sample{coordinate: -float64(number), degrees: lat})
+	case "E":
+		b.eastWest = append(b.eastWest, sample{coordinate: float64(number), degrees: lon})
+	case "W":
+		b.eastWest = append(b.eastWest, sample{coordinate: -float64(number), degrees: lon})
+	}
+}
+
+// Model fits each axis by least squares. Points more than three standard deviations from the first fit, usually
+// geocoding or data entry errors, are dropped and the axis is fitted again.
+func (b *Builder) Model() (Model, error) {
+	northSouth, err := fitAxis(b.northSouth)
+	if err != nil {
+		return Model{}, fmt.Errorf("north south axis: %w", err)
+	}
+	eastWest, err := fitAxis(b.eastWest)
+	if err != nil {
+		return Model{}, fmt.Errorf("east west axis: %w", err)
+	}
+	return Model{NorthSouth: northSouth, EastWest: eastWest}, nil
+}
+
+func fitAxis(samples []sample) (Axis, error) {
+	axis, ok := leastSquares(samples)
+	if !ok {
+		return Axis{}, ErrTooFewSamples
+	}
+
+	inliers := make([]sample, 0, len(samples))
+	for _, s := range samples {
+		if math.Abs(s.degrees-(axis.Intercept+axis.Slope*s.coordinate)) <= 3*axis.RMSE {
+			inliers = append(inliers, s)
+		}
+	}
+	if refit, ok := leastSquares(inliers); ok {
+		return refit, nil
+	}
+	return axis, nil
+}
+
+// leastSquares fits a line through the samples. ok is false with fewer than MinSamples samples or when every sample
+// has the same coordinate.
+func leastSquares(samples []sample) (Axis, bool) {
+	n := float64(len(samples))
+	if len(samples) < MinSamples {
+		return Axis{}, false
+	}
+	var sumX, sumY float64
+	for _, s := range samples {
+		sumX += s.coordinate
+		sumY += s.degrees
+	}
+	meanX, meanY := sumX/n, sumY/n
+	var covariance, variance float64
+	for _, s := range samples {
+		covariance += (s.coordinate - meanX) * (s.degrees - meanY)
+		variance += (s.coordinate - meanX) * (s.coordinate - meanX)
+	}
+	if variance == 0 {
+		return Axis{}, false
+	}
+
+	axis := Axis{Slope: covariance / variance, Samples: len(samples)}
+	axis.Intercept = meanY - axis.Slope*meanX
+	var squaredError float64
+	for _, s := range samples {
+		residual := s.degrees - (axis.Intercept + axis.Slope*s.coordinate)
+		squaredError += residual * residual
+	}
+	axis.RMSE = math.Sqrt(squaredError / n)
+	return axis, true
+}
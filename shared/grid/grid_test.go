@@ -0,0 +1,101 @@
+package grid
+
+import (
+	"math"
+	"path/filepath"
+	"testing"
+)
+
+// State and Madison, and the approximate degrees per address number in Chicago, where 800 numbers are a mile.
+const (
+	originLat       = 41.88195
+	originLon       = -87.62775
+	latPerNumber    = 1.0 / 800 / 69.05
+	lonPerNumber    = 1.0 / 800 / 51.45
+	toleranceDegree = 1e-6
+)
+
+func buildTestModel(t *testing.T) Model {
+	var builder Builder
+	for number := 0; number < 8000; number += 20 {
+		builder.Add(number, "N", originLat+latPerNumber*float64(number), -87.6)
+		builder.Add(number, "S", originLat-latPerNumber*float64(number), -87.7)
+		builder.Add(number, "E", 41.9, originLon+lonPerNumber*float64(number))
+		builder.Add(number, "W", 41.8, originLon-lonPerNumber*float64(number))
+	}
+	// A misplaced point and a point with no grid direction.
+	builder.Add(1200, "N", 42.5, -87.6)
+	builder.Add(1200, "", 40, -80)
+
+	model, err := builder.Model()
+	if err != nil {
+		t.Fatalf("Expected no errors fitting the grid. Found %v", err)
+	}
+	return model
+}
+
+func TestBuilderFitsGrid(t *testing.T) {
+	model := buildTestModel(t)
+
+	lat, lon := model.Locate(800, -1200)
+	if math.Abs(lat-(originLat+800*latPerNumber)) > toleranceDegree || math.Abs(lon-(originLon-1200*lonPerNumber)) > toleranceDegree {
+		t.Errorf("Unexpected location for 800 N 1200 W. actual: %f,%f", lat, lon)
+	}
+	if model.NorthSouth.Samples != 800 {
+		t.Errorf("Expected the misplaced point to be dropped. actual samples: %d", model.NorthSouth.Samples)
+	}
+
+	northSouth, eastWest := model.Coordinate(lat, lon)
+	if northSouth != 800 || eastWest != -1200 {
+		t.Errorf("Expected the coordinate to round trip. actual: %d %d", northSouth, eastWest)
+	}
+}
+
+func TestBuilderRequiresSamples(t *testing.T) {
+	var builder Builder
+	builder.Add(800, "N", originLat, originLon)
+	if _, err := builder.Model(); err == nil {
+		t.Errorf("Expected an error with too few samples")
+	}
+}
+
+func TestParse(t *testing.T) {
+	cases := map[string][2]int{
+		"800 N 1200 W":   {800, -1200},
+		"n800 w1200":     {800, -1200},
+		"1200 E, 3900 S": {-3900, 1200},
+		"0 N 0 E":        {0, 0},
+	}
+	for input, expected := range cases {
+		northSouth, eastWest, err := Parse(input)
+		if err != nil || northSouth != expected[0] || eastWest != expected[1] {
+			t.Errorf("Error parsing %q. actual: %d %d err: %v", input, northSouth, eastWest, err)
+		}
+	}
+	for _, input := range []string{"800 N", "800 N 1200 S", "800 N 1200 W Chicago", "1200 W Madison St"} {
+		if _, _, err := Parse(input); err != ErrBadCoordinate {
+			t.Errorf("Expected ErrBadCoordinate for %q. actual: %v", input, err)
+		}
+	}
+}
+
+func TestFormat(t *testing.T) {
+	if actual := Format(800, -1200); actual != "800 N 1200 W" {
+		t.Errorf("Unexpected format. actual: %s", actual)
+	}
+	if actual := Format(-3900, 0); actual != "3900 S 0 E" {
+		t.Errorf("Unexpected format. actual: %s", actual)
+	}
+}
+
+func TestSaveAndLoadModel(t *testing.T) {
+	model := buildTestModel(t)
+	path := filepath.Join(t.TempDir(), "grid.json")
+	if err := model.Save(path); err != nil {
+		t.Fatalf("Expected no errors saving the model. Found %v", err)
+	}
+	loaded, err := LoadModel(path)
+	if err != nil || loaded != model {
+		t.Errorf("Expected the loaded model to match. actual: %v err: %v", loaded, err)
+	}
+}
@@ -0,0 +1,74 @@
+package housenumber
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// House numbers as they are written in address data. Most are plain integers, but half addresses (1234 1/2), lettered
+// addresses (1234A) and ranges covering more than one number (12-14) are distinct addresses and keep their parts, so
+// they are not indexed over the plain number.
+
+var ErrBadNumber = errors.New("house number must start with digits, for example 1234, 1234A, 1234 1/2 or 12-14")
+
+// HouseNumber is a parsed house number. Number is the numeric part, or the low end of a range. Low and High are only
+// set for ranges. Full is the standardized house number as it is written, for example "1234 1/2".
+type HouseNumber struct {
+	Full     string
+	Number   int
+	Fraction string
+	Suffix   string
+	Low      int
+	High     int
+}
+
+// Plain reports whether the house number is a single integer without a fraction or letter.
+func (h HouseNumber) Plain() bool {
+	return h.Fraction == "" && h.Suffix == "" && h.High == 0
+}
+
+var (
+	// 1234, 1234A, 1234 A, 1234 1/2, 1234-1/2, 1234½, 1234A 1/2. The fraction must be set apart, since 12341/2 could
+	// as well be a typo.
+	numberPattern = regexp.MustCompile(`^(\d+)\s*([A-Z])?(?:(?:\s*-\s*|\s+)(\d+/\d+))?$`)
+	// 12-14, 12 & 14, 12 TO 14
+	rangePattern = regexp.MustCompile(`^(\d+)\s*(?:-|&|AND|TO|THRU)\s*(\d+)$`)
+
+	fractionReplacer = strings.NewReplacer("½", " 1/2", "¼", " 1/4", "¾", " 3/4", ".5", " 1/2")
+)
+
+// Parse reads a house number. A range must go from low to high, so 14-12 is rejected.
+func Parse(value string) (HouseNumber, error) {
+	value = strings.Join(strings.Fields(fractionReplacer.Replace(strings.ToUpper(value))), " ")
+
+	if match := rangePattern.FindStringSubmatch(value); match != nil {
+		low, lowErr := strconv.Atoi(match[1])
+		high, highErr := strconv.Atoi(match[2])
+		if lowErr != nil || highErr != nil || high <= low {
+			return HouseNumber{}, fmt.Errorf("%w: %q", ErrBadNumber, value)
+		}
+		return HouseNumber{Full: fmt.Sprintf("%d-%d", low, high), Number: low, Low: low, High: high}, nil
+	}
+
+	match := numberPattern.FindStringSubmatch(value)
+	if match == nil {
+		return HouseNumber{}, fmt.Errorf("%w: %q", ErrBadNumber, value)
+	}
+	number, err := strconv.Atoi(match[1])
+	if err != nil {
+		return HouseNumber{}, fmt.Errorf("%w: %q", ErrBadNumber, value)
+	}
+	h := HouseNumber{Number: number, Suffix: match[2], Fraction: match[3]}
+	h.Full = strings.TrimSpace(fmt.Sprintf("%d%s %s", h.Number, h.Suffix, h.Fraction))
+	return h, nil
+}
+
+// IsFraction reports whether a token is a fraction such as 1/2, written after a house number.
+func IsFraction(token string) bool {
+	return fractionPattern.MatchString(token)
+}
+
+var fractionPattern = regexp.MustCompile(`^\d+/\d+$`)
@@ -0,0 +1,46 @@
+package housenumber
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	cases := map[string]HouseNumber{
+		"1234":         {Full: "1234", Number: 1234},
+		" 77777   ":    {Full: "77777", Number: 77777},
+		"1234A":        {Full: "1234A", Number: 1234, Suffix: "A"},
+		"1234 b":       {Full: "1234B", Number: 1234, Suffix: "B"},
+		"1234 1/2":     {Full: "1234 1/2", Number: 1234, Fraction: "1/2"},
+		"1234-1/2":     {Full: "1234 1/2", Number: 1234, Fraction: "1/2"},
+		"1234½":        {Full: "1234 1/2", Number: 1234, Fraction: "1/2"},
+		"999.5":        {Full: "999 1/2", Number: 999, Fraction: "1/2"},
+		"12-14":        {Full: "12-14", Number: 12, Low: 12, High: 14},
+		"1234 to 1236": {Full: "1234-1236", Number: 1234, Low: 1234, High: 1236},
+	}
+	for input, expected := range cases {
+		actual, err := Parse(input)
+		if err != nil {
+			t.Errorf("Expected no errors parsing %q. Found %v", input, err)
+		}
+		if actual != expected {
+			t.Errorf("Error parsing %q. actual: %+v expected: %+v", input, actual, expected)
+		}
+	}
+}
+
+func TestParseRejectsMalformedNumbers(t *testing.T) {
+	for _, input := range []string{"", "ABC", "1/2", "14-12", "12345ABCdef GHIJ", "777771/2"} {
+		if _, err := Parse(input); !errors.Is(err, ErrBadNumber) {
+			t.Errorf("Expected ErrBadNumber for %q. actual: %v", input, err)
+		}
+	}
+}
+
+func TestPlain(t *testing.T) {
+	plain, _ := Parse("1234")
+	fraction, _ := Parse("1234 1/2")
+	if !plain.Plain() || fraction.Plain() {
+		t.Errorf("Expected only 1234 to be plain. actual: %t %t", plain.Plain(), fraction.Plain())
+	}
+}
@@ -1,16 +1,47 @@
 package mapping
 
-// EsAddress is the representation of the data in ElasticSearch document form
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// EsAddress is the representation of the data in ElasticSearch document form. The alias fields hold the long forms of
+// the standardized prefix and suffix (NORTH for N, AVENUE for AVE) so either spelling is searchable. FullAddress is the
+// single line address, indexed for search as you type, and StreetPhonetic the phonetic key of the street name used to
+// match misspellings. UnitDesignator and UnitId are set on unit level points, for example APT and 4B, and empty on
+// building points. HouseNumber is the house number as written, for example 1234 1/2 or 12-14, and the other Number
+// fields its parts when it is not a plain integer. ZipCityMismatch flags a city the ZIP code is not known by, for review.
+// Municipality, Township, Ward and CensusTract name the areas containing the address, and Pin is the 14 digit PIN of its
+// parcel. Id is the document ID and is not part of the document body.
 type EsAddress struct {
-	Number       int     `json:"number"`
-	StreetPrefix string  `json:"street_prefix"`
-	Street       string  `json:"street"`
-	StreetSuffix string  `json:"street_suffix"`
-	City         string  `json:"city"`
-	State        string  `json:"state"`
-	Zip5         string  `json:"zip_5"`
-	ZipLast4     string  `json:"zip_last_4"`
-	LatLong      LatLong `json:"lat_long"`
+	Id                string  `json:"-"`
+	Number            int     `json:"number"`
+	HouseNumber       string  `json:"house_number,omitempty"`
+	NumberFraction    string  `json:"number_fraction,omitempty"`
+	NumberSuffix      string  `json:"number_suffix,omitempty"`
+	NumberLow         int     `json:"number_low,omitempty"`
+	NumberHigh        int     `json:"number_high,omitempty"`
+	StreetPrefix      string  `json:"street_prefix"`
+	StreetPrefixAlias string  `json:"street_prefix_alias,omitempty"`
+	Street            string  `json:"street"`
+	StreetPhonetic    string  `json:"street_phonetic,omitempty"`
+	StreetSuffix      string  `json:"street_suffix"`
+	StreetSuffixAlias string  `json:"street_suffix_alias,omitempty"`
+	UnitDesignator    string  `json:"unit_designator,omitempty"`
+	UnitId            string  `json:"unit_id,omitempty"`
+	City              string  `json:"city"`
+	State             string  `json:"state"`
+	Zip5              string  `json:"zip_5"`
+	ZipLast4          string  `json:"zip_last_4"`
+	LatLong           LatLong `json:"lat_long"`
+	FullAddress       string  `json:"full_address,omitempty"`
+	ZipCityMismatch   bool    `json:"zip_city_mismatch,omitempty"`
+	Municipality      string  `json:"municipality,omitempty"`
+	Township          string  `json:"township,omitempty"`
+	Ward              string  `json:"ward,omitempty"`
+	CensusTract       string  `json:"census_tract,omitempty"`
+	Pin               string  `json:"pin,omitempty"`
 }
 
 type LatLong struct {
@@ -18,3 +49,31 @@ type LatLong struct {
 	Latitude  float64 `json:"lat"`
 }
 
+// FormatAddress builds a single line mailing address, skipping empty parts. The unit follows the street.
+func FormatAddress(doc EsAddress) string {
+	number := doc.HouseNumber
+	if number == "" {
+		number = strconv.Itoa(doc.Number)
+	}
+	street := strings.Join(strings.Fields(fmt.Sprintf("%s %s %s %s %s", number, doc.StreetPrefix, doc.Street, doc.StreetSuffix, FormatUnit(doc.UnitDesignator, doc.UnitId))), " ")
+	stateZip := strings.TrimSpace(doc.State + " " + doc.Zip5)
+	return strings.Join(nonEmpty(street, doc.City, stateZip), ", ")
+}
+
+// FormatUnit writes a secondary unit, for example "APT 4B" or "#4B".
+func FormatUnit(designator string, id string) string {
+	if designator == "#" {
+		return designator + id
+	}
+	return strings.TrimSpace(designator + " " + id)
+}
+
+func nonEmpty(values ...string) []string {
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		if v != "" {
+			result = append(result, v)
+		}
+	}
+	return result
+}
@@ -0,0 +1,46 @@
+package mapping
+
+import (
+	"testing"
+)
+
+func TestFormatAddress(t *testing.T) {
+	doc := EsAddress{
+		Number:       1200,
+		StreetPrefix: "W",
+		Street:       "MADISON",
+		StreetSuffix: "ST",
+		City:         "CHICAGO",
+		State:        "IL",
+		Zip5:         "60607",
+	}
+	expected := "1200 W MADISON ST, CHICAGO, IL 60607"
+	if actual := FormatAddress(doc); actual != expected {
+		t.Errorf("Error formatting address. actual: %s expected: %s", actual, expected)
+	}
+
+	doc.StreetPrefix = ""
+	doc.City = ""
+	expected = "1200 MADISON ST, IL 60607"
+	if actual := FormatAddress(doc); actual != expected {
+		t.Errorf("Error formatting address with missing parts. actual: %s expected: %s", actual, expected)
+	}
+
+	doc.UnitDesignator, doc.UnitId = "APT", "4B"
+	expected = "1200 MADISON ST APT 4B, IL 60607"
+	if actual := FormatAddress(doc); actual != expected {
+		t.Errorf("Error formatting address with a unit. actual: %s expected: %s", actual, expected)
+	}
+
+	doc.UnitDesignator = "#"
+	expected = "1200 MADISON ST #4B, IL 60607"
+	if actual := FormatAddress(doc); actual != expected {
+		t.Errorf("Error formatting address with a # unit. actual: %s expected: %s", actual, expected)
+	}
+
+	doc.HouseNumber = "1200 1/2"
+	expected = "1200 1/2 MADISON ST #4B, IL 60607"
+	if actual := FormatAddress(doc); actual != expected {
+		t.Errorf("Error formatting address with a fraction. actual: %s expected: %s", actual, expected)
+	}
+}
@@ -0,0 +1,48 @@
+package parcel
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Cook County property index numbers. A PIN is 14 digits: area, subarea, block, parcel and a 4 digit unit number, often
+// written 17-08-437-012-0000. The first 10 digits identify the parcel and the unit number tells apart the condominium
+// units on it, so a 10 digit PIN is the parcel with unit number 0000.
+
+const (
+	// PinLength is the number of digits in a full PIN.
+	PinLength = 14
+	// ParcelLength is the number of digits identifying the parcel, without the unit number.
+	ParcelLength = 10
+)
+
+// NormalizePin returns the 14 digit form of a PIN written with or without dashes, spaces or dots. A 10 digit PIN gets
+// the unit number 0000. ok is false when the value is not a PIN.
+func NormalizePin(value string) (string, bool) {
+	switch pin, ok := Digits(value); {
+	case !ok:
+		return "", false
+	case len(pin) == PinLength:
+		return pin, true
+	case len(pin) == ParcelLength:
+		return pin + "0000", true
+	default:
+		return "", false
+	}
+}
+
+// Digits returns the digits of a PIN written with dashes, spaces or dots. ok is false when it has any other characters.
+// The number of digits is not checked.
+func Digits(value string) (string, bool) {
+	var digits strings.Builder
+	for _, r := range value {
+		switch {
+		case unicode.IsDigit(r):
+			digits.WriteRune(r)
+		case r == '-' || r == '.' || unicode.IsSpace(r):
+		default:
+			return "", false
+		}
+	}
+	return digits.String(), true
+}
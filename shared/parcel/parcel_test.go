@@ -0,0 +1,23 @@
+package parcel
+
+import "testing"
+
+func TestNormalizePin(t *testing.T) {
+	cases := map[string]string{
+		"17-08-437-012-0000": "17084370120000",
+		"17084370121001":     "17084370121001",
+		" 17 08 437 012 ":    "17084370120000",
+		"17.08.437.012.1001": "17084370121001",
+	}
+	for input, expected := range cases {
+		if actual, ok := NormalizePin(input); !ok || actual != expected {
+			t.Errorf("Error normalizing PIN %q. actual: %s %t expected: %s", input, actual, ok, expected)
+		}
+	}
+
+	for _, invalid := range []string{"", "17-08-437", "170843701200001", "17-08-437-012-000A"} {
+		if actual, ok := NormalizePin(invalid); ok {
+			t.Errorf("Expected %q to not be a PIN. actual: %s", invalid, actual)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package phonetic
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Phonetic keys for street names. Ingest stores the key of each street and the geocoder searches it, so names that
+// sound alike, such as ASHLAND and ASHLEND, share a key even when they are spelled differently.
+
+// Soundex returns the American Soundex code of a word, for example "ROBERT" and "RUPERT" both return "R163". Characters
+// other than ASCII letters are ignored. An empty string is returned when the word has no letters.
+func Soundex(word string) string {
+	code := make([]byte, 0, 4)
+	var last byte
+	for _, r := range strings.ToUpper(word) {
+		if r > unicode.MaxASCII || !unicode.IsLetter(r) {
+			continue
+		}
+		digit := soundexDigits[r-'A']
+		if len(code) == 0 {
+			code = append(code, byte(r))
+			last = digit
+			continue
+		}
+		switch {
+		case digit == 'H':
+			// H and W do not separate letters with the same code.
+		case digit == '0':
+			// Vowels separate letters with the same code.
+			last = digit
+		case digit != last:
+			code = append(code, digit)
+			last = digit
+		}
+		if len(code) == 4 {
+			break
+		}
+	}
+	if len(code) == 0 {
+		return ""
+	}
+	for len(code) < 4 {
+		code = append(code, '0')
+	}
+	return string(code)
+}
+
+// soundexDigits holds the code of each letter from A to Z. 0 marks vowels and H marks H and W.
+const soundexDigits = "0123012H02245501262301H202"
+
+// Key returns the phonetic key of a street name, the Soundex code of each word separated by spaces. Words containing
+// digits, such as 63RD, are kept as they are so numbered streets only match themselves.
+func Key(street string) string {
+	words := strings.Fields(strings.ToUpper(street))
+	keys := make([]string, 0, len(words))
+	for _, word := range words {
+		if strings.IndexFunc(word, unicode.IsDigit) >= 0 {
+			keys = append(keys, word)
+			continue
+		}
+		if key := Soundex(word); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return strings.Join(keys, " ")
+}
@@ -0,0 +1,39 @@
+package phonetic
+
+import "testing"
+
+func TestSoundex(t *testing.T) {
+	cases := map[string]string{
+		"ROBERT":   "R163",
+		"Rupert":   "R163",
+		"ASHCRAFT": "A261",
+		"TYMCZAK":  "T522",
+		"PFISTER":  "P236",
+		"LEE":      "L000",
+		"O'HARE":   "O600",
+		"123":      "",
+	}
+	for word, expected := range cases {
+		if actual := Soundex(word); actual != expected {
+			t.Errorf("Unexpected Soundex code for %s. actual: %s expected: %s", word, actual, expected)
+		}
+	}
+}
+
+func TestSoundexMatchesMisspellings(t *testing.T) {
+	pairs := [][]string{{"ASHLAND", "ASHLEND"}, {"MADISON", "MADDISON"}, {"HALSTED", "HALSTEAD"}}
+	for _, pair := range pairs {
+		if Soundex(pair[0]) != Soundex(pair[1]) {
+			t.Errorf("Expected %s and %s to share a code. actual: %s %s", pair[0], pair[1], Soundex(pair[0]), Soundex(pair[1]))
+		}
+	}
+}
+
+func TestKey(t *testing.T) {
+	if actual := Key("martin  luther king"); actual != "M635 L360 K520" {
+		t.Errorf("Unexpected street key. actual: %s", actual)
+	}
+	if actual := Key("63RD"); actual != "63RD" {
+		t.Errorf("Expected numbered streets to keep their name. actual: %s", actual)
+	}
+}
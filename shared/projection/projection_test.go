@@ -0,0 +1,47 @@
+package projection
+
+import (
+	"math"
+	"testing"
+)
+
+func TestIllinoisEastOrigin(t *testing.T) {
+	x, y := IllinoisEast.Forward(IllinoisEast.LatitudeOfOrigin, IllinoisEast.CentralMeridian)
+	// The false easting of 300000 meters is 984250 US survey feet.
+	if math.Abs(x-984250) > 1e-6 || math.Abs(y) > 1e-6 {
+		t.Errorf("Expected the origin to project to the false easting. actual: %f,%f", x, y)
+	}
+}
+
+func TestIllinoisEastAlongCentralMeridian(t *testing.T) {
+	// On the central meridian the northing is the scaled length of the meridian arc from the latitude of origin.
+	lat := 41.88
+	_, y := IllinoisEast.Forward(lat, IllinoisEast.CentralMeridian)
+	expected := IllinoisEast.ScaleFactor * (meridianArc(radians(lat)) - meridianArc(radians(IllinoisEast.LatitudeOfOrigin))) / USSurveyFoot
+	if math.Abs(y-expected) > 1e-6 {
+		t.Errorf("Error projecting along the central meridian. actual: %f expected: %f", y, expected)
+	}
+	// A degree of latitude is about 111 km, or 364000 feet.
+	if math.Abs(y/(lat-IllinoisEast.LatitudeOfOrigin)-364000) > 1000 {
+		t.Errorf("Expected about 364000 feet per degree of latitude. actual: %f", y/(lat-IllinoisEast.LatitudeOfOrigin))
+	}
+}
+
+func TestIllinoisEastRoundTrip(t *testing.T) {
+	// State and Madison, O'Hare, the Indiana line and the far south suburbs.
+	points := [][2]float64{{41.88204, -87.62782}, {41.9786, -87.9048}, {41.6, -87.5246}, {41.4697, -87.7}}
+	for _, point := range points {
+		x, y := IllinoisEast.Forward(point[0], point[1])
+		lat, lon := IllinoisEast.Inverse(x, y)
+		// 1e-8 degrees is about a millimeter.
+		if math.Abs(lat-point[0]) > 1e-8 || math.Abs(lon-point[1]) > 1e-8 {
+			t.Errorf("Error round tripping %v through %f,%f. actual: %f,%f", point, x, y, lat, lon)
+		}
+	}
+
+	x, y := IllinoisEast.Forward(41.88204, -87.62782)
+	// Chicago is east of the central meridian, roughly 1.1 to 1.2 million feet east and 1.8 to 1.95 million north.
+	if x < 1100000 || x > 1210000 || y < 1800000 || y > 1955000 {
+		t.Errorf("Expected State and Madison inside the Chicago range. actual: %f,%f", x, y)
+	}
+}
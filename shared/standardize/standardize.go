@@ -0,0 +1,304 @@
+package standardize
+
+import "strings"
+
+// Standardization of address parts to USPS Publication 28 abbreviations. Both ingest and query parsing go through this
+// package so indexed data and queries use the same spellings.
+
+// Suffix returns the USPS standard abbreviation for a street suffix (Publication 28 Appendix C1), for example "AVENUE",
+// "AVE" and "AV" all return "AVE". ok is false when the value is not a known suffix.
+func Suffix(value string) (string, bool) {
+	abbreviation, ok := suffixes[normalize(value)]
+	return abbreviation, ok
+}
+
+// SuffixLongForm returns the primary name for a standard suffix abbreviation, for example "AVE" returns "AVENUE".
+func SuffixLongForm(abbreviation string) string {
+	return suffixLongForms[normalize(abbreviation)]
+}
+
+// Directional returns the USPS abbreviation for a directional (Publication 28 Appendix B), for example "NORTH" and "N"
+// both return "N". ok is false when the value is not a directional.
+func Directional(value string) (string, bool) {
+	abbreviation, ok := directionals[normalize(value)]
+	return abbreviation, ok
+}
+
+// DirectionalLongForm returns the spelled out directional for an abbreviation, for example "SW" returns "SOUTHWEST".
+func DirectionalLongForm(abbreviation string) string {
+	return directionalLongForms[normalize(abbreviation)]
+}
+
+// SuffixOrOriginal standardizes a suffix, returning the input unchanged when it is not a known suffix.
+func SuffixOrOriginal(value string) string {
+	if abbreviation, ok := Suffix(value); ok {
+		return abbreviation
+	}
+	return value
+}
+
+// DirectionalOrOriginal standardizes a directional, returning the input unchanged when it is not a directional.
+func DirectionalOrOriginal(value string) string {
+	if abbreviation, ok := Directional(value); ok {
+		return abbreviation
+	}
+	return value
+}
+
+func normalize(value string) string {
+	return strings.ToUpper(strings.TrimSpace(strings.ReplaceAll(value, ".", "")))
+}
+
+var (
+	suffixes             = make(map[string]string)
+	suffixLongForms      = make(map[string]string)
+	directionals         = make(map[string]string)
+	directionalLongForms = make(map[string]string)
+)
+
+func init() {
+	for _, entry := range suffixTable {
+		// A few plurals share the singular abbreviation. Keep the first, singular, long form.
+		if _, exists := suffixLongForms[entry.abbreviation]; !exists {
+			suffixLongForms[entry.abbreviation] = entry.long
+		}
+		suffixes[entry.long] = entry.abbreviation
+		suffixes[entry.abbreviation] = entry.abbreviation
+		for _, variant := range entry.variants {
+			suffixes[variant] = entry.abbreviation
+		}
+	}
+	for _, entry := range directionalTable {
+		directionalLongForms[entry.abbreviation] = entry.long
+		directionals[entry.long] = entry.abbreviation
+		directionals[entry.abbreviation] = entry.abbreviation
+		for _, variant := range entry.variants {
+			directionals[variant] = entry.abbreviation
+		}
+	}
+}
+
+type tableEntry struct {
+	long         string
+	abbreviation string
+	variants     []string
+}
+
+var directionalTable = []tableEntry{
+	{"NORTH", "N", nil},
+	{"SOUTH", "S", nil},
+	{"EAST", "E", nil},
+	{"WEST", "W", nil},
+	{"NORTHEAST", "NE", []string{"NORTH EAST"}},
+	{"NORTHWEST", "NW", []string{"NORTH WEST"}},
+	{"SOUTHEAST", "SE", []string{"SOUTH EAST"}},
+	{"SOUTHWEST", "SW", []string{"SOUTH WEST"}},
+}
+
+// suffixTable is Publication 28 Appendix C1: primary suffix name, standard abbreviation and commonly used variants.
+var suffixTable = []tableEntry{
+	{"ALLEY", "ALY", []string{"ALLEE", "ALLY"}},
+	{"ANNEX", "ANX", []string{"ANEX", "ANNX"}},
+	{"ARCADE", "ARC", nil},
+	{"AVENUE", "AVE", []string{"AV", "AVEN", "AVENU", "AVN", "AVNUE"}},
+	{"BAYOU", "BYU", []string{"BAYOO"}},
+	{"BEACH", "BCH", nil},
+	{"BEND", "BND", nil},
+	{"BLUFF", "BLF", []string{"BLUF"}},
+	{"BLUFFS", "BLFS", nil},
+	{"BOTTOM", "BTM", []string{"BOT", "BOTTM"}},
+	{"BOULEVARD", "BLVD", []string{"BOUL", "BOULV"}},
+	{"BRANCH", "BR", []string{"BRNCH"}},
+	{"BRIDGE", "BRG", []string{"BRDGE"}},
+	{"BROOK", "BRK", nil},
+	{"BROOKS", "BRKS", nil},
+	{"BURG", "BG", nil},
+	{"BURGS", "BGS", nil},
+	{"BYPASS", "BYP", []string{"BYPA", "BYPAS", "BYPS"}},
+	{"CAMP", "CP", []string{"CMP"}},
+	{"CANYON", "CYN", []string{"CANYN", "CNYN"}},
+	{"CAPE", "CPE", nil},
+	{"CAUSEWAY", "CSWY", []string{"CAUSWA"}},
+	{"CENTER", "CTR", []string{"CEN", "CENT", "CENTR", "CENTRE", "CNTER", "CNTR"}},
+	{"CENTERS", "CTRS", nil},
+	{"CIRCLE", "CIR", []string{"CIRC", "CIRCL", "CRCL", "CRCLE"}},
+	{"CIRCLES", "CIRS", nil},
+	{"CLIFF", "CLF", nil},
+	{"CLIFFS", "CLFS", nil},
+	{"CLUB", "CLB", nil},
+	{"COMMON", "CMN", nil},
+	{"COMMONS", "CMNS", nil},
+	{"CORNER", "COR", nil},
+	{"CORNERS", "CORS", nil},
+	{"COURSE", "CRSE", nil},
+	{"COURT", "CT", nil},
+	{"COURTS", "CTS", nil},
+	{"COVE", "CV", nil},
+	{"COVES", "CVS", nil},
+	{"CREEK", "CRK", nil},
+	{"CRESCENT", "CRES", []string{"CRSENT", "CRSNT"}},
+	{"CREST", "CRST", nil},
+	{"CROSSING", "XING", []string{"CRSSNG"}},
+	{"CROSSROAD", "XRD", nil},
+	{"CROSSROADS", "XRDS", nil},
+	{"CURVE", "CURV", nil},
+	{"DALE", "DL", nil},
+	{"DAM", "DM", nil},
+	{"DIVIDE", "DV", []string{"DIV", "DVD"}},
+	{"DRIVE", "DR", []string{"DRIV", "DRV"}},
+	{"DRIVES", "DRS", nil},
+	{"ESTATE", "EST", nil},
+	{"ESTATES", "ESTS", nil},
+	{"EXPRESSWAY", "EXPY", []string{"EXP", "EXPR", "EXPRESS", "EXPW"}},
+	{"EXTENSION", "EXT", []string{"EXTN", "EXTNSN"}},
+	{"EXTENSIONS", "EXTS", nil},
+	{"FALL", "FALL", nil},
+	{"FALLS", "FLS", nil},
+	{"FERRY", "FRY", []string{"FRRY"}},
+	{"FIELD", "FLD", nil},
+	{"FIELDS", "FLDS", nil},
+	{"FLAT", "FLT", nil},
+	{"FLATS", "FLTS", nil},
+	{"FORD", "FRD", nil},
+	{"FORDS", "FRDS", nil},
+	{"FOREST", "FRST", []string{"FORESTS"}},
+	{"FORGE", "FRG", []string{"FORG"}},
+	{"FORGES", "FRGS", nil},
+	{"FORK", "FRK", nil},
+	{"FORKS", "FRKS", nil},
+	{"FORT", "FT", []string{"FRT"}},
+	{"FREEWAY", "FWY", []string{"FREEWY", "FRWAY", "FRWY"}},
+	{"GARDEN", "GDN", []string{"GARDN", "GRDEN", "GRDN"}},
+	{"GARDENS", "GDNS", []string{"GRDNS"}},
+	{"GATEWAY", "GTWY", []string{"GATEWY", "GATWAY", "GTWAY"}},
+	{"GLEN", "GLN", nil},
+	{"GLENS", "GLNS", nil},
+	{"GREEN", "GRN", nil},
+	{"GREENS", "GRNS", nil},
+	{"GROVE", "GRV", []string{"GROV"}},
+	{"GROVES", "GRVS", nil},
+	{"HARBOR", "HBR", []string{"HARB", "HARBR", "HRBOR"}},
+	{"HARBORS", "HBRS", nil},
+	{"HAVEN", "HVN", nil},
+	{"HEIGHTS", "HTS", []string{"HT"}},
+	{"HIGHWAY", "HWY", []string{"HIGHWY", "HIWAY", "HIWY", "HWAY"}},
+	{"HILL", "HL", nil},
+	{"HILLS", "HLS", nil},
+	{"HOLLOW", "HOLW", []string{"HLLW", "HOLLOWS", "HOLWS"}},
+	{"INLET", "INLT", nil},
+	{"ISLAND", "IS", []string{"ISLND"}},
+	{"ISLANDS", "ISS", []string{"ISLNDS"}},
+	{"ISLE", "ISLE", []string{"ISLES"}},
+	{"JUNCTION", "JCT", []string{"JCTION", "JCTN", "JUNCTN", "JUNCTON"}},
+	{"JUNCTIONS", "JCTS", []string{"JCTNS"}},
+	{"KEY", "KY", nil},
+	{"KEYS", "KYS", nil},
+	{"KNOLL", "KNL", []string{"KNOL"}},
+	{"KNOLLS", "KNLS", nil},
+	{"LAKE", "LK", nil},
+	{"LAKES", "LKS", nil},
+	{"LAND", "LAND", nil},
+	{"LANDING", "LNDG", []string{"LNDNG"}},
+	{"LANE", "LN", nil},
+	{"LIGHT", "LGT", nil},
+	{"LIGHTS", "LGTS", nil},
+	{"LOAF", "LF", nil},
+	{"LOCK", "LCK", nil},
+	{"LOCKS", "LCKS", nil},
+	{"LODGE", "LDG", []string{"LDGE", "LODG"}},
+	{"LOOP", "LOOP", []string{"LOOPS"}},
+	{"MALL", "MALL", nil},
+	{"MANOR", "MNR", nil},
+	{"MANORS", "MNRS", nil},
+	{"MEADOW", "MDW", nil},
+	{"MEADOWS", "MDWS", []string{"MEDOWS"}},
+	{"MEWS", "MEWS", nil},
+	{"MILL", "ML", nil},
+	{"MILLS", "MLS", nil},
+	{"MISSION", "MSN", []string{"MISSN", "MSSN"}},
+	{"MOTORWAY", "MTWY", nil},
+	{"MOUNT", "MT", []string{"MNT"}},
+	{"MOUNTAIN", "MTN", []string{"MNTAIN", "MNTN", "MOUNTIN", "MTIN"}},
+	{"MOUNTAINS", "MTNS", []string{"MNTNS"}},
+	{"NECK", "NCK", nil},
+	{"ORCHARD", "ORCH", []string{"ORCHRD"}},
+	{"OVAL", "OVAL", []string{"OVL"}},
+	{"OVERPASS", "OPAS", nil},
+	{"PARK", "PARK", []string{"PRK"}},
+	{"PARKS", "PARK", nil},
+	{"PARKWAY", "PKWY", []string{"PARKWY", "PKWAY", "PKY"}},
+	{"PARKWAYS", "PKWY", []string{"PKWYS"}},
+	{"PASS", "PASS", nil},
+	{"PASSAGE", "PSGE", nil},
+	{"PATH", "PATH", []string{"PATHS"}},
+	{"PIKE", "PIKE", []string{"PIKES"}},
+	{"PINE", "PNE", nil},
+	{"PINES", "PNES", nil},
+	{"PLACE", "PL", nil},
+	{"PLAIN", "PLN", nil},
+	{"PLAINS", "PLNS", nil},
+	{"PLAZA", "PLZ", []string{"PLZA"}},
+	{"POINT", "PT", nil},
+	{"POINTS", "PTS", nil},
+	{"PORT", "PRT", nil},
+	{"PORTS", "PRTS", nil},
+	{"PRAIRIE", "PR", []string{"PRR"}},
+	{"RADIAL", "RADL", []string{"RAD", "RADIEL"}},
+	{"RAMP", "RAMP", nil},
+	{"RANCH", "RNCH", []string{"RANCHES", "RNCHS"}},
+	{"RAPID", "RPD", nil},
+	{"RAPIDS", "RPDS", nil},
+	{"REST", "RST", nil},
+	{"RIDGE", "RDG", []string{"RDGE"}},
+	{"RIDGES", "RDGS", nil},
+	{"RIVER", "RIV", []string{"RVR", "RIVR"}},
+	{"ROAD", "RD", nil},
+	{"ROADS", "RDS", nil},
+	{"ROUTE", "RTE", nil},
+	{"ROW", "ROW", nil},
+	{"RUE", "RUE", nil},
+	{"RUN", "RUN", nil},
+	{"SHOAL", "SHL", nil},
+	{"SHOALS", "SHLS", nil},
+	{"SHORE", "SHR", []string{"SHOAR"}},
+	{"SHORES", "SHRS", []string{"SHOARS"}},
+	{"SKYWAY", "SKWY", nil},
+	{"SPRING", "SPG", []string{"SPNG", "SPRNG"}},
+	{"SPRINGS", "SPGS", []string{"SPNGS", "SPRNGS"}},
+	{"SPUR", "SPUR", []string{"SPURS"}},
+	{"SQUARE", "SQ", []string{"SQR", "SQRE", "SQU"}},
+	{"SQUARES", "SQS", []string{"SQRS"}},
+	{"STATION", "STA", []string{"STATN", "STN"}},
+	{"STRAVENUE", "STRA", []string{"STRAV", "STRAVEN", "STRAVN", "STRVN", "STRVNUE"}},
+	{"STREAM", "STRM", []string{"STREME"}},
+	{"STREET", "ST", []string{"STR", "STRT"}},
+	{"STREETS", "STS", nil},
+	{"SUMMIT", "SMT", []string{"SUMIT", "SUMITT"}},
+	{"TERRACE", "TER", []string{"TERR"}},
+	{"THROUGHWAY", "TRWY", nil},
+	{"TRACE", "TRCE", []string{"TRACES"}},
+	{"TRACK", "TRAK", []string{"TRACKS", "TRK", "TRKS"}},
+	{"TRAFFICWAY", "TRFY", nil},
+	{"TRAIL", "TRL", []string{"TRAILS", "TRLS"}},
+	{"TRAILER", "TRLR", []string{"TRLRS"}},
+	{"TUNNEL", "TUNL", []string{"TUNEL", "TUNLS", "TUNNELS", "TUNNL"}},
+	{"TURNPIKE", "TPKE", []string{"TRNPK", "TURNPK"}},
+	{"UNDERPASS", "UPAS", nil},
+	{"UNION", "UN", nil},
+	{"UNIONS", "UNS", nil},
+	{"VALLEY", "VLY", []string{"VALLY", "VLLY"}},
+	{"VALLEYS", "VLYS", nil},
+	{"VIADUCT", "VIA", []string{"VDCT", "VIADCT"}},
+	{"VIEW", "VW", nil},
+	{"VIEWS", "VWS", nil},
+	{"VILLAGE", "VLG", []string{"VILL", "VILLAG", "VILLG", "VILLIAGE"}},
+	{"VILLAGES", "VLGS", nil},
+	{"VILLE", "VL", nil},
+	{"VISTA", "VIS", []string{"VIST", "VST", "VSTA"}},
+	{"WALK", "WALK", []string{"WALKS"}},
+	{"WALL", "WALL", nil},
+	{"WAY", "WAY", []string{"WY"}},
+	{"WAYS", "WAYS", nil},
+	{"WELL", "WL", nil},
+	{"WELLS", "WLS", nil},
+}
@@ -0,0 +1,80 @@
+package standardize
+
+import "testing"
+
+func TestSuffixVariantsShareAbbreviation(t *testing.T) {
+	for _, variant := range []string{"AVENUE", "AVE", "AV", "Avenue", "ave.", " AVNUE "} {
+		if actual, ok := Suffix(variant); !ok || actual != "AVE" {
+			t.Errorf("Expected %q to standardize to AVE. actual: %s ok: %t", variant, actual, ok)
+		}
+	}
+	if _, ok := Suffix("MADISON"); ok {
+		t.Errorf("Expected MADISON to not be a suffix.")
+	}
+}
+
+func TestSuffixLongForm(t *testing.T) {
+	if actual := SuffixLongForm("AVE"); actual != "AVENUE" {
+		t.Errorf("Expected AVENUE. actual: %s", actual)
+	}
+	// PARK and PARKS share an abbreviation. The singular name is the long form.
+	if actual := SuffixLongForm("PARK"); actual != "PARK" {
+		t.Errorf("Expected PARK. actual: %s", actual)
+	}
+	if actual := SuffixLongForm("PKWY"); actual != "PARKWAY" {
+		t.Errorf("Expected PARKWAY. actual: %s", actual)
+	}
+}
+
+func TestDirectional(t *testing.T) {
+	cases := map[string]string{"N": "N", "NORTH": "N", "North": "N", "SW": "SW", "SOUTHWEST": "SW", "south west": "SW"}
+	for input, expected := range cases {
+		if actual, ok := Directional(input); !ok || actual != expected {
+			t.Errorf("Expected %q to standardize to %s. actual: %s ok: %t", input, expected, actual, ok)
+		}
+	}
+	if actual := DirectionalLongForm("SW"); actual != "SOUTHWEST" {
+		t.Errorf("Expected SOUTHWEST. actual: %s", actual)
+	}
+}
+
+func TestOrOriginalKeepsUnknownValues(t *testing.T) {
+	if actual := SuffixOrOriginal("streetSuffix"); actual != "streetSuffix" {
+		t.Errorf("Expected unknown suffix to be unaltered. actual: %s", actual)
+	}
+	if actual := DirectionalOrOriginal("Street"); actual != "Street" {
+		t.Errorf("Expected unknown directional to be unaltered. actual: %s", actual)
+	}
+	if actual := SuffixOrOriginal("Street"); actual != "ST" {
+		t.Errorf("Expected ST. actual: %s", actual)
+	}
+}
+
+func TestUnitDesignator(t *testing.T) {
+	cases := map[string]string{"APARTMENT": "APT", "Apt.": "APT", "SUITE": "STE", "ste": "STE", "#": "#", "FLOOR": "FL"}
+	for variant, expected := range cases {
+		if actual, ok := UnitDesignator(variant); !ok || actual != expected {
+			t.Errorf("Expected %q to standardize to %s. actual: %s ok: %t", variant, expected, actual, ok)
+		}
+	}
+	if _, ok := UnitDesignator("4B"); ok {
+		t.Errorf("Expected 4B to not be a designator.")
+	}
+}
+
+func TestSplitUnit(t *testing.T) {
+	cases := map[string][2]string{
+		"APT 4B":    {"APT", "4B"},
+		"Suite 200": {"STE", "200"},
+		"#4B":       {"#", "4B"},
+		"4B":        {"#", "4B"},
+		"basement":  {"BSMT", ""},
+		"  ":        {"", ""},
+		"UNIT 12 A": {"UNIT", "12 A"},
+	}
+	for input, expected := range cases {
+		if designator, id := SplitUnit(input); designator != expected[0] || id != expected[1] {
+			t.Errorf("Error splitting %q. actual: %s %s expected: %v", input, designator, id, expected)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package standardize
+
+import "strings"
+
+// UnitDesignator returns the USPS abbreviation for a secondary unit designator (Publication 28 Appendix C2), for
+// example "APARTMENT" and "APT" both return "APT". ok is false when the value is not a designator.
+func UnitDesignator(value string) (string, bool) {
+	abbreviation, ok := unitDesignators[normalize(value)]
+	return abbreviation, ok
+}
+
+// SplitUnit splits a secondary unit such as "APT 4B", "#4B" or "Suite 200" into its standardized designator and
+// identifier. An identifier without a known designator, such as "4B", gets the designator "#". Both are empty for an
+// empty unit.
+func SplitUnit(value string) (designator string, id string) {
+	tokens := strings.Fields(strings.ReplaceAll(normalize(value), "#", " # "))
+	if len(tokens) == 0 {
+		return "", ""
+	}
+	if abbreviation, ok := UnitDesignator(tokens[0]); ok {
+		return abbreviation, strings.Join(tokens[1:], " ")
+	}
+	return "#", strings.Join(tokens, " ")
+}
+
+var unitDesignators = make(map[string]string)
+
+func init() {
+	for _, entry := range unitDesignatorTable {
+		unitDesignators[entry.long] = entry.abbreviation
+		unitDesignators[entry.abbreviation] = entry.abbreviation
+		for _, variant := range entry.variants {
+			unitDesignators[variant] = entry.abbreviation
+		}
+	}
+}
+
+// unitDesignatorTable is Publication 28 Appendix C2, with "#" for units given by number alone.
+var unitDesignatorTable = []tableEntry{
+	{"#", "#", []string{"NO", "NUMBER"}},
+	{"APARTMENT", "APT", []string{"APPT"}},
+	{"BASEMENT", "BSMT", nil},
+	{"BUILDING", "BLDG", []string{"BLD"}},
+	{"DEPARTMENT", "DEPT", nil},
+	{"FLOOR", "FL", []string{"FLR"}},
+	{"FRONT", "FRNT", nil},
+	{"HANGAR", "HNGR", nil},
+	{"KEY", "KEY", nil},
+	{"LOBBY", "LBBY", nil},
+	{"LOT", "LOT", nil},
+	{"LOWER", "LOWR", nil},
+	{"OFFICE", "OFC", nil},
+	{"PENTHOUSE", "PH", nil},
+	{"PIER", "PIER", nil},
+	{"REAR", "REAR", nil},
+	{"ROOM", "RM", nil},
+	{"SIDE", "SIDE", nil},
+	{"SLIP", "SLIP", nil},
+	{"SPACE", "SPC", nil},
+	{"STOP", "STOP", nil},
+	{"SUITE", "STE", []string{"SUIT"}},
+	{"TRAILER", "TRLR", nil},
+	{"UNIT", "UNIT", nil},
+	{"UPPER", "UPPR", nil},
+}
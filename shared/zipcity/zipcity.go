@@ -0,0 +1,139 @@
+package zipcity
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// A city other than the preferred one is acceptable for a ZIP code when at least AcceptableShare of the addresses with
+// the ZIP, and at least AcceptableCount of them, have it. Rarer cities are treated as mistakes.
+const (
+	AcceptableShare = 0.05
+	AcceptableCount = 3
+)
+
+// Cities are the cities of a ZIP5. City is the preferred city, the one most addresses with the ZIP have, and Acceptable
+// the other cities in common use for it. State is the most common state.
+type Cities struct {
+	City       string   `json:"city"`
+	State      string   `json:"state"`
+	Acceptable []string `json:"acceptable,omitempty"`
+}
+
+// Table maps each ZIP5 to its cities.
+type Table map[string]Cities
+
+// Accepts reports whether the city is the preferred or an acceptable city of the ZIP code. Cities of unknown ZIP codes
+// are accepted, since there is nothing to compare them with.
+func (t Table) Accepts(zip5 string, city string) bool {
+	cities, ok := t[zip5]
+	if !ok {
+		return true
+	}
+	city = normalize(city)
+	if city == cities.City {
+		return true
+	}
+	for _, acceptable := range cities.Acceptable {
+		if city == acceptable {
+			return true
+		}
+	}
+	return false
+}
+
+// Zips returns the ZIP codes the city is preferred or acceptable for, in order.
+func (t Table) Zips(city string) []string {
+	city = normalize(city)
+	var zips []string
+	for zip5 := range t {
+		if t.Accepts(zip5, city) {
+			zips = append(zips, zip5)
+		}
+	}
+	sort.Strings(zips)
+	return zips
+}
+
+// Save writes the table as JSON.
+func (t Table) Save(path string) error {
+	encoded, err := json.MarshalIndent(t, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, encoded, 0666)
+}
+
+// LoadTable reads a table written by Save.
+func LoadTable(path string) (Table, error) {
+	file, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var table Table
+	if err := json.Unmarshal(file, &table); err != nil {
+		return nil, fmt.Errorf("could not decode ZIP city table %s: %w", path, err)
+	}
+	return table, nil
+}
+
+// Votes counts the cities and states of addresses by ZIP5.
+type Votes map[string]*votes
+
+type votes struct {
+	total  int
+	cities map[string]int
+	states map[string]int
+}
+
+// Add counts the city and state of an address. Addresses without a ZIP code or city are not counted.
+func (v Votes) Add(zip5 string, city string, state string) {
+	city = normalize(city)
+	if zip5 == "" || city == "" {
+		return
+	}
+	counts, ok := v[zip5]
+	if !ok {
+		counts = &votes{cities: make(map[string]int), states: make(map[string]int)}
+		v[zip5] = counts
+	}
+	counts.total++
+	counts.cities[city]++
+	if state = normalize(state); state != "" {
+		counts.states[state]++
+	}
+}
+
+// Table returns the majority city and state of each ZIP code, along with its acceptable cities. Ties go to the name
+// that sorts first, so the table does not depend on the order the addresses were read.
+func (v Votes) Table() Table {
+	table := make(Table, len(v))
+	for zip5, counts := range v {
+		cities := Cities{City: majority(counts.cities), State: majority(counts.states)}
+		for city, count := range counts.cities {
+			if city != cities.City && count >= AcceptableCount && float64(count) >= AcceptableShare*float64(counts.total) {
+				cities.Acceptable = append(cities.Acceptable, city)
+			}
+		}
+		sort.Strings(cities.Acceptable)
+		table[zip5] = cities
+	}
+	return table
+}
+
+func majority(counts map[string]int) string {
+	best, bestCount := "", 0
+	for value, count := range counts {
+		if count > bestCount || (count == bestCount && value < best) {
+			best, bestCount = value, count
+		}
+	}
+	return best
+}
+
+func normalize(value string) string {
+	return strings.Join(strings.Fields(strings.ToUpper(value)), " ")
+}
@@ -0,0 +1,75 @@
+package zipcity
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func buildTestTable() Table {
+	votes := make(Votes)
+	for i := 0; i < 90; i++ {
+		votes.Add("60453", "Oak Lawn", "IL")
+	}
+	for i := 0; i < 8; i++ {
+		votes.Add("60453", "HOMETOWN", "IL")
+	}
+	// A typo and an address without a city.
+	votes.Add("60453", "OAK LAWM", "IL")
+	votes.Add("60453", "", "IL")
+	// A tie.
+	votes.Add("60804", "CICERO", "IL")
+	votes.Add("60804", "BERWYN", "IL")
+	return votes.Table()
+}
+
+func TestVotesPickMajorityCity(t *testing.T) {
+	table := buildTestTable()
+	expected := Table{
+		"60453": {City: "OAK LAWN", State: "IL", Acceptable: []string{"HOMETOWN"}},
+		"60804": {City: "BERWYN", State: "IL"},
+	}
+	if !reflect.DeepEqual(table, expected) {
+		t.Errorf("Unexpected table. actual: %v expected: %v", table, expected)
+	}
+}
+
+func TestTableAccepts(t *testing.T) {
+	table := buildTestTable()
+	cases := []struct {
+		zip5, city string
+		expected   bool
+	}{
+		{"60453", "OAK LAWN", true},
+		{"60453", "hometown", true},
+		{"60453", "OAK LAWM", false},
+		{"60453", "CHICAGO", false},
+		{"99999", "ANYWHERE", true},
+	}
+	for _, c := range cases {
+		if actual := table.Accepts(c.zip5, c.city); actual != c.expected {
+			t.Errorf("Unexpected acceptance of %s %s. actual: %v", c.zip5, c.city, actual)
+		}
+	}
+	if zips := table.Zips("Hometown"); !reflect.DeepEqual(zips, []string{"60453"}) {
+		t.Errorf("Unexpected ZIP codes for HOMETOWN. actual: %v", zips)
+	}
+	if zips := table.Zips("CICERO"); len(zips) != 0 {
+		t.Errorf("Expected no ZIP codes for a city that lost the vote. actual: %v", zips)
+	}
+}
+
+func TestSaveAndLoadTable(t *testing.T) {
+	table := buildTestTable()
+	path := filepath.Join(t.TempDir(), "zip_cities.json")
+	if err := table.Save(path); err != nil {
+		t.Fatalf("Expected no errors saving the table. Found %v", err)
+	}
+	loaded, err := LoadTable(path)
+	if err != nil {
+		t.Fatalf("Expected no errors loading the table. Found %v", err)
+	}
+	if !reflect.DeepEqual(loaded, table) {
+		t.Errorf("Expected the loaded table to match. actual: %v expected: %v", loaded, table)
+	}
+}